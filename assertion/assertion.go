@@ -1,17 +1,91 @@
 package assertion
 
+import (
+	"context"
+
+	"github.com/lucasvmiguel/integration/mock"
+)
+
 type Assertion interface {
 	Setup() error
 	Assert() error
 }
 
+// ContextAssertion is an assertion that can be canceled by a context
+type ContextAssertion interface {
+	Assertion
+	AssertContext(ctx context.Context) error
+}
+
+// AssertContext runs an assertion with the context if it's a ContextAssertion, otherwise the context is ignored
+func AssertContext(ctx context.Context, assertion Assertion) error {
+	contextAssertion, ok := assertion.(ContextAssertion)
+	if ok {
+		return contextAssertion.AssertContext(ctx)
+	}
+	return assertion.Assert()
+}
+
 // AnyHTTP returns true if the assertions contains at least one HTTP assertion
 func AnyHTTP(assertions []Assertion) bool {
 	for _, assertion := range assertions {
-		_, ok := assertion.(*HTTP)
+		_, ok := httpAssertion(assertion)
 		if ok {
 			return ok
 		}
 	}
 	return false
 }
+
+// AnyGlobalHTTP returns true if the assertions contains at least one HTTP assertion
+// that registers its responder in the global httpmock transport
+func AnyGlobalHTTP(assertions []Assertion) bool {
+	for _, assertion := range assertions {
+		httpAssertion, ok := httpAssertion(assertion)
+		if ok && httpAssertion.Mock == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// WithMock returns a copy of the assertions where the HTTP assertions without a Mock use the registry.
+// The assertions passed as param are not modified.
+func WithMock(assertions []Assertion, registry mock.Registry) []Assertion {
+	if registry == nil {
+		return assertions
+	}
+
+	bound := make([]Assertion, len(assertions))
+	for i, assertion := range assertions {
+		bound[i] = withMock(assertion, registry)
+	}
+	return bound
+}
+
+func withMock(assertion Assertion, registry mock.Registry) Assertion {
+	switch a := assertion.(type) {
+	case *HTTP:
+		if a.Mock == nil {
+			withMock := *a
+			withMock.Mock = registry
+			return &withMock
+		}
+	case *Eventually:
+		eventually := *a
+		eventually.Assertion = withMock(a.Assertion, registry)
+		return &eventually
+	}
+	return assertion
+}
+
+// httpAssertion returns the HTTP assertion, including one retried by Eventually
+func httpAssertion(assertion Assertion) (*HTTP, bool) {
+	switch a := assertion.(type) {
+	case *HTTP:
+		return a, true
+	case *Eventually:
+		return httpAssertion(a.Assertion)
+	}
+	return nil, false
+}
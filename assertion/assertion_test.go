@@ -0,0 +1,56 @@
+package assertion
+
+import (
+	"testing"
+
+	"github.com/lucasvmiguel/integration/mock"
+)
+
+func TestAnyGlobalHTTP(t *testing.T) {
+	if AnyGlobalHTTP([]Assertion{&SQL{}}) {
+		t.Fatal("SQL assertions don't use the global httpmock transport")
+	}
+
+	if !AnyGlobalHTTP([]Assertion{&SQL{}, &HTTP{}}) {
+		t.Fatal("HTTP assertions without a mock use the global httpmock transport")
+	}
+
+	if AnyGlobalHTTP([]Assertion{&HTTP{Mock: mock.NewTransport()}}) {
+		t.Fatal("HTTP assertions with a mock don't use the global httpmock transport")
+	}
+
+	if !AnyGlobalHTTP([]Assertion{&Eventually{Assertion: &HTTP{}}}) {
+		t.Fatal("HTTP assertions retried by Eventually use the global httpmock transport")
+	}
+}
+
+func TestWithMock(t *testing.T) {
+	transport := mock.NewTransport()
+	other := mock.NewTransport()
+	withoutMock := &HTTP{}
+	withMock := &HTTP{Mock: other}
+
+	eventually := &Eventually{Assertion: withoutMock}
+
+	assertions := WithMock([]Assertion{withoutMock, withMock, &SQL{}, eventually}, transport)
+
+	if withoutMock.Mock != nil {
+		t.Fatal("assertions passed as param should not be modified")
+	}
+
+	if assertions[0].(*HTTP).Mock != transport {
+		t.Fatal("HTTP assertion without a mock should use the registry")
+	}
+
+	if assertions[1].(*HTTP).Mock != other {
+		t.Fatal("HTTP assertion with a mock should keep its own mock")
+	}
+
+	if _, ok := assertions[2].(*SQL); !ok {
+		t.Fatal("other assertions should be kept")
+	}
+
+	if assertions[3].(*Eventually).Assertion.(*HTTP).Mock != transport || eventually.Assertion != withoutMock {
+		t.Fatal("HTTP assertion retried by Eventually should use the registry")
+	}
+}
@@ -0,0 +1,128 @@
+package assertion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultEventuallyTimeout  = 5 * time.Second
+	defaultEventuallyInterval = 100 * time.Millisecond
+)
+
+// Eventually retries an assertion until it passes or the timeout is reached.
+// It's useful to assert side effects that happen asynchronously, after the response is sent.
+type Eventually struct {
+	// Assertion that will be retried
+	// eg: &assertion.SQL{}
+	Assertion Assertion
+
+	// Timeout is the maximum time to wait for the assertion to pass
+	// default: 5 seconds
+	Timeout time.Duration
+
+	// Interval is the time to wait before retrying the assertion
+	// default: 100 milliseconds
+	Interval time.Duration
+
+	// Backoff multiplies the interval after each attempt
+	// default: 1 (the interval doesn't change)
+	// eg: 2
+	Backoff float64
+}
+
+// EventuallyError is returned when an Eventually assertion doesn't pass before its timeout
+type EventuallyError struct {
+	// Attempts is how many times the assertion ran
+	Attempts int
+	// Waited is how long the assertion was retried
+	Waited time.Duration
+	// Last is the error of the last attempt
+	Last error
+}
+
+func (e *EventuallyError) Error() string {
+	return fmt.Sprintf("assertion didn't pass after %d attempts in %s, last error: %v", e.Attempts, e.Waited.Round(time.Millisecond), e.Last)
+}
+
+func (e *EventuallyError) Unwrap() error {
+	return e.Last
+}
+
+// Setup sets up the assertion that will be retried
+func (a *Eventually) Setup() error {
+	err := a.validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate assertion: %w", err)
+	}
+
+	return a.Assertion.Setup()
+}
+
+// Assert retries the assertion until it passes or the timeout is reached
+func (a *Eventually) Assert() error {
+	return a.AssertContext(context.Background())
+}
+
+// AssertContext retries the assertion until it passes, the timeout is reached or the context is done
+func (a *Eventually) AssertContext(ctx context.Context) error {
+	err := a.validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate assertion: %w", err)
+	}
+
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = defaultEventuallyTimeout
+	}
+
+	interval := a.Interval
+	if interval == 0 {
+		interval = defaultEventuallyInterval
+	}
+
+	backoff := a.Backoff
+	if backoff == 0 {
+		backoff = 1
+	}
+
+	start := time.Now()
+	deadline := start.Add(timeout)
+
+	for attempts := 1; ; attempts++ {
+		err := AssertContext(ctx, a.Assertion)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			return &EventuallyError{Attempts: attempts, Waited: time.Since(start), Last: err}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ctx.Err(), &EventuallyError{Attempts: attempts, Waited: time.Since(start), Last: err})
+		case <-time.After(interval):
+		}
+
+		interval = time.Duration(float64(interval) * backoff)
+	}
+}
+
+func (a *Eventually) validate() error {
+	if a.Assertion == nil {
+		return errors.New("assertion is required")
+	}
+
+	if a.Timeout < 0 || a.Interval < 0 {
+		return errors.New("timeout and interval can't be negative")
+	}
+
+	if a.Backoff != 0 && a.Backoff < 1 {
+		return errors.New("backoff must be greater than or equal to 1")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package assertion
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// attempts is an assertion that passes after a number of attempts
+type attempts struct {
+	passAfter int
+	count     int
+}
+
+func (a *attempts) Setup() error {
+	return nil
+}
+
+func (a *attempts) Assert() error {
+	a.count++
+	if a.count < a.passAfter {
+		return fmt.Errorf("attempt %d failed", a.count)
+	}
+	return nil
+}
+
+func TestEventuallyAssert_Success(t *testing.T) {
+	inner := &attempts{passAfter: 3}
+	assertion := Eventually{Assertion: inner, Interval: time.Millisecond}
+
+	err := assertion.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = assertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if inner.count != 3 {
+		t.Fatalf("assertion should have run 3 times, it ran %d times", inner.count)
+	}
+}
+
+func TestEventuallyAssert_Timeout(t *testing.T) {
+	inner := &attempts{passAfter: 1000}
+	assertion := Eventually{
+		Assertion: inner,
+		Timeout:   50 * time.Millisecond,
+		Interval:  5 * time.Millisecond,
+		Backoff:   2,
+	}
+
+	err := assertion.Assert()
+
+	var eventuallyErr *EventuallyError
+	if !errors.As(err, &eventuallyErr) {
+		t.Fatalf("it should fail due to timeout, it got %v", err)
+	}
+
+	if eventuallyErr.Attempts != inner.count || eventuallyErr.Last.Error() != fmt.Sprintf("attempt %d failed", inner.count) {
+		t.Fatalf("it should report the last error, it got %v", err)
+	}
+
+	// with backoff 2, the attempts are at 0, 5, 15 and 35 milliseconds
+	if inner.count < 2 || inner.count > 4 {
+		t.Fatalf("assertion should have run up to 4 times, it ran %d times", inner.count)
+	}
+}
+
+func TestEventuallyAssert_Invalid(t *testing.T) {
+	for _, assertion := range []Eventually{{}, {Assertion: &SQL{}, Backoff: 0.5}, {Assertion: &SQL{}, Timeout: -1}} {
+		err := assertion.Assert()
+		if err == nil {
+			t.Fatalf("it should fail due to an invalid assertion %+v", assertion)
+		}
+	}
+}
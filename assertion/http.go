@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
-	"github.com/kinbiko/jsonassert"
 	"github.com/lucasvmiguel/integration/expect"
 	"github.com/lucasvmiguel/integration/internal/utils"
+	"github.com/lucasvmiguel/integration/matcher"
 	"github.com/lucasvmiguel/integration/mock"
 
 	"github.com/jarcoal/httpmock"
@@ -20,11 +21,14 @@ type HTTP struct {
 	Request expect.Request
 	// Response mocks a fake response to avoid your test making real http request over the internet
 	Response mock.Response
+	// Mock is the registry where the mocked response is registered (eg: mock.NewTransport()).
+	// If it's nil, the Mock of the test case is used. If both are nil, the global httpmock transport is used.
+	Mock mock.Registry
 }
 
 // Setup sets up if request will be called as expected
 func (a *HTTP) Setup() error {
-	httpmock.RegisterResponder(a.method(), a.Request.URL,
+	a.registry().RegisterResponder(a.method(), a.Request.URL,
 		func(req *http.Request) (*http.Response, error) {
 			if req.Body != nil {
 				defer req.Body.Close()
@@ -36,10 +40,14 @@ func (a *HTTP) Setup() error {
 				reqBodyString := string(reqBody)
 
 				if utils.IsJSON(a.Request.Body) {
-					je := utils.JsonError{}
-					jsonassert.New(&je).Assertf(reqBodyString, a.Request.Body)
-					if je.Err != nil {
-						return nil, fmt.Errorf("response body is a JSON. response body does not match: %v", je.Err.Error())
+					err := matcher.JSON(reqBodyString, a.Request.Body)
+					if err != nil {
+						return nil, fmt.Errorf("response body is a JSON. response body does not match: %v", err)
+					}
+				} else if matcher.IsPlaceholder(a.Request.Body) {
+					err := matcher.Match(reqBodyString, a.Request.Body)
+					if err != nil {
+						return nil, fmt.Errorf("%s: request body does not match %s: %w", a.Request.URL, a.Request.Body, err)
 					}
 				} else {
 					if reqBodyString != a.Request.Body {
@@ -78,18 +86,22 @@ func (a *HTTP) Assert() error {
 	}
 
 	reqInfo := fmt.Sprintf("%s %s", a.method(), a.Request.URL)
-	callCountInfo := httpmock.GetCallCountInfo()
 
 	expectedTimes := a.Request.Times
 	if expectedTimes == 0 {
 		expectedTimes = 1
 	}
 
-	times, ok := callCountInfo[reqInfo]
+	times, ok := a.registry().CallCount(a.method(), a.Request.URL)
 	if !ok {
 		return fmt.Errorf("HTTP request '%s' has never been called", reqInfo)
 	}
 
+	errs := a.registry().Errors(a.method(), a.Request.URL)
+	if len(errs) > 0 {
+		return fmt.Errorf("HTTP request '%s' has been called with an unexpected request: %w", reqInfo, errs[0])
+	}
+
 	if expectedTimes > times || expectedTimes < times {
 		return fmt.Errorf("HTTP request '%s' has been called %d times, expected %d", reqInfo, times, expectedTimes)
 	}
@@ -97,6 +109,13 @@ func (a *HTTP) Assert() error {
 	return nil
 }
 
+func (a *HTTP) registry() mock.Registry {
+	if a.Mock == nil {
+		return globalRegistry{}
+	}
+	return a.Mock
+}
+
 func (a *HTTP) method() string {
 	method := a.Request.Method
 	if method == "" {
@@ -112,3 +131,44 @@ func (a *HTTP) validate() error {
 
 	return nil
 }
+
+// globalErrors keeps the errors returned by the responders registered in the global httpmock transport,
+// by method and URL
+var globalErrors = struct {
+	errors map[string][]error
+	mux    sync.Mutex
+}{errors: map[string][]error{}}
+
+// globalRegistry registers responders in the global httpmock transport
+type globalRegistry struct{}
+
+func (globalRegistry) RegisterResponder(method, url string, responder mock.Responder) {
+	key := fmt.Sprintf("%s %s", method, url)
+
+	globalErrors.mux.Lock()
+	delete(globalErrors.errors, key)
+	globalErrors.mux.Unlock()
+
+	httpmock.RegisterResponder(method, url, func(req *http.Request) (*http.Response, error) {
+		resp, err := responder(req)
+		if err != nil {
+			globalErrors.mux.Lock()
+			globalErrors.errors[key] = append(globalErrors.errors[key], err)
+			globalErrors.mux.Unlock()
+		}
+		return resp, err
+	})
+}
+
+func (globalRegistry) CallCount(method, url string) (int, bool) {
+	times, ok := httpmock.GetCallCountInfo()[fmt.Sprintf("%s %s", method, url)]
+	return times, ok
+}
+
+func (globalRegistry) Errors(method, url string) []error {
+	globalErrors.mux.Lock()
+	defer globalErrors.mux.Unlock()
+
+	errs := globalErrors.errors[fmt.Sprintf("%s %s", method, url)]
+	return append([]error(nil), errs...)
+}
@@ -423,4 +423,173 @@ func TestHTTPSetup_FailedRequestBody(t *testing.T) {
 	if err == nil {
 		t.Fatal(err)
 	}
+
+	err = assertion.Assert()
+	if err == nil || !strings.Contains(err.Error(), "unexpected request") {
+		t.Fatalf("it should fail due to the request body, it got %v", err)
+	}
+}
+
+func TestHTTPAssert_RequestCalledWithMockTransport(t *testing.T) {
+	transport := mock.NewTransport()
+
+	assertion := HTTP{
+		Request: expect.Request{
+			URL:    "https://jsonplaceholder.typicode.com/posts/1",
+			Method: http.MethodGet,
+		},
+		Response: mock.Response{
+			StatusCode: http.StatusAccepted,
+		},
+		Mock: transport,
+	}
+
+	err := assertion.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := transport.Client().Get("https://jsonplaceholder.typicode.com/posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("status code should be %d but it got %d", http.StatusAccepted, resp.StatusCode)
+	}
+
+	err = assertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPAssert_MockTransportsAreIsolated(t *testing.T) {
+	url := "https://jsonplaceholder.typicode.com/posts/1"
+	first := mock.NewTransport()
+	second := mock.NewTransport()
+
+	firstAssertion := HTTP{Request: expect.Request{URL: url, Times: 2}, Mock: first}
+	secondAssertion := HTTP{Request: expect.Request{URL: url}, Mock: second}
+
+	for _, assertion := range []*HTTP{&firstAssertion, &secondAssertion} {
+		err := assertion.Setup()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, client := range []*http.Client{first.Client(), first.Client(), second.Client()} {
+		_, err := client.Get(url)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := firstAssertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = secondAssertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPAssert_MockTransportDoesNotInterceptDefaultTransport(t *testing.T) {
+	transport := mock.NewTransport()
+
+	assertion := HTTP{
+		Request: expect.Request{URL: "http://unknown"},
+		Mock:    transport,
+	}
+
+	err := assertion.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = transport.Client().Get("http://unknown/other")
+	if err == nil {
+		t.Fatal("it should fail because there is no responder registered for the URL")
+	}
+
+	err = assertion.Assert()
+	if err == nil {
+		t.Fatal("it should fail because the request was not sent through the transport")
+	}
+}
+
+func TestHTTPAssert_RequestCalledWithMockServer(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	assertion := HTTP{
+		Request: expect.Request{
+			URL:    "/posts",
+			Method: http.MethodPost,
+			Body:   `{"title": "foo", "userId": "<<PRESENCE>>"}`,
+			Times:  2,
+		},
+		Response: mock.Response{
+			StatusCode: http.StatusCreated,
+			Body:       `{"id": 1}`,
+		},
+		Mock: server,
+	}
+
+	err := assertion.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := http.Post(server.URL()+"/posts", "application/json", strings.NewReader(`{"title": "foo", "userId": 1}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("status code should be %d but it got %d", http.StatusCreated, resp.StatusCode)
+		}
+	}
+
+	err = assertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPSetup_FailedRequestBodyWithMockServer(t *testing.T) {
+	server := mock.NewServer()
+	defer server.Close()
+
+	assertion := HTTP{
+		Request: expect.Request{
+			URL:    "/posts",
+			Method: http.MethodPost,
+			Body:   `{"title": "foo"}`,
+		},
+		Mock: server,
+	}
+
+	err := assertion.Setup()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Post(server.URL()+"/posts", "application/json", strings.NewReader(`{"title": "bar"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status code should be %d but it got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	err = assertion.Assert()
+	if err == nil || !strings.Contains(err.Error(), "unexpected request") {
+		t.Fatalf("it should fail due to the request body, it got %v", err)
+	}
 }
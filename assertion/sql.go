@@ -1,12 +1,14 @@
 package assertion
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/matcher"
 )
 
 // SQL asserts a SQL query
@@ -25,18 +27,30 @@ func (a *SQL) Setup() error {
 }
 
 // Assert checks if query returns the expected result
-// Reference: https://kylewbanks.com/blog/query-result-to-map-in-golang
 func (a *SQL) Assert() error {
+	return a.AssertContext(context.Background())
+}
+
+// AssertContext checks if query returns the expected result, the context is used to cancel the query
+// Reference: https://kylewbanks.com/blog/query-result-to-map-in-golang
+func (a *SQL) AssertContext(ctx context.Context) error {
 	err := a.validate()
 	if err != nil {
 		return fmt.Errorf("failed to validate assertion: %w", err)
 	}
 
+	if a.Query.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, a.Query.Timeout)
+		defer cancel()
+	}
+
 	result := []map[string]interface{}{}
-	rows, err := a.DB.Query(a.Query.Statement, a.Query.Params...)
+	rows, err := a.DB.QueryContext(ctx, a.Query.Statement, a.Query.Params...)
 	if err != nil {
 		return fmt.Errorf("failed to execute SQL query: %w", err)
 	}
+	defer rows.Close()
 	cols, _ := rows.Columns()
 
 	for rows.Next() {
@@ -62,6 +76,11 @@ func (a *SQL) Assert() error {
 		result = append(result, m)
 	}
 
+	err = rows.Err()
+	if err != nil {
+		return fmt.Errorf("failed to read SQL query result: %w", err)
+	}
+
 	numResults := len(result)
 	numExpectedResult := len(a.Result)
 	if numResults != numExpectedResult {
@@ -70,7 +89,7 @@ func (a *SQL) Assert() error {
 
 	for i, r := range result {
 		for key, val := range r {
-			if fmt.Sprint(a.Result[i][key]) != fmt.Sprint(val) {
+			if !matchValue(a.Result[i][key], val) {
 				return fmt.Errorf("SQL result number %d don't match, it should be %v but it got %v", i, a.Result[i], r)
 			}
 		}
@@ -79,6 +98,14 @@ func (a *SQL) Assert() error {
 	return nil
 }
 
+// matchValue returns true if the value of a column is the expected one, or it matches the expected placeholder
+func matchValue(expected, value interface{}) bool {
+	if placeholder, ok := expected.(string); ok && matcher.IsPlaceholder(placeholder) {
+		return matcher.Match(value, placeholder) == nil
+	}
+	return fmt.Sprint(expected) == fmt.Sprint(value)
+}
+
 func (a *SQL) validate() error {
 	if a.DB == nil {
 		return errors.New("database is required")
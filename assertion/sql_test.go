@@ -1,10 +1,13 @@
 package assertion
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
@@ -71,6 +74,31 @@ func TestSQLAssert_SuccessWithJoin(t *testing.T) {
 	}
 }
 
+func TestSQLAssert_SuccessWithPlaceholders(t *testing.T) {
+	db, _ := connectToDatabase()
+	assertion := SQL{
+		DB: db,
+		Query: call.Query{
+			Statement: "SELECT id, title, description, category_id FROM products",
+		},
+		Result: expect.Result{
+			{"id": "<<ANY_NUMBER>>", "title": "<<REGEX:^foo\\d$>>", "description": "bar1", "category_id": "<<RANGE:1,2>>"},
+			{"id": "<<ANY_NUMBER>>", "title": "foo2", "description": "<<REGEX:^bar>>", "category_id": 1},
+		},
+	}
+
+	err := assertion.Assert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertion.Result[1]["category_id"] = "<<RANGE:2,3>>"
+	err = assertion.Assert()
+	if err == nil {
+		t.Fatal("it should fail due to the category out of range")
+	}
+}
+
 func TestSQLAssert_FailedToQuery(t *testing.T) {
 	db, _ := connectToDatabase()
 	assertion := SQL{
@@ -106,6 +134,26 @@ func TestSQLAssert_FailedResult(t *testing.T) {
 	}
 }
 
+func TestSQLAssertContext_Canceled(t *testing.T) {
+	db, _ := connectToDatabase()
+	assertion := SQL{
+		DB: db,
+		Query: call.Query{
+			Statement: "SELECT id FROM products",
+			Timeout:   time.Second,
+		},
+		Result: expect.Result{{"id": 1}, {"id": 2}},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := assertion.AssertContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("it should fail due to the context canceled, it got %v", err)
+	}
+}
+
 func connectToDatabase() (*sql.DB, error) {
 	db, err := sql.Open("sqlite3", "./database.db")
 	if err != nil {
@@ -0,0 +1,37 @@
+// Package auth provides the credentials sent with HTTP, GRPC and Websocket calls
+package auth
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// Provider provides the credentials of a call
+type Provider interface {
+	// Authorization returns the value of the authorization header
+	// eg: Bearer token
+	Authorization(ctx context.Context) (string, error)
+}
+
+// Basic authenticates with a username and a password
+type Basic struct {
+	Username string
+	Password string
+}
+
+// Authorization returns the basic authorization header
+func (b Basic) Authorization(ctx context.Context) (string, error) {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(b.Username+":"+b.Password)), nil
+}
+
+// Bearer authenticates with a static token
+type Bearer struct {
+	// Token sent with the calls
+	// eg: eyJhbGciOiJIUzI1NiJ9...
+	Token string
+}
+
+// Authorization returns the bearer authorization header
+func (b Bearer) Authorization(ctx context.Context) (string, error) {
+	return "Bearer " + b.Token, nil
+}
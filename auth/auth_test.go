@@ -0,0 +1,248 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBasic(t *testing.T) {
+	authorization, err := Basic{Username: "foo", Password: "bar"}.Authorization(context.Background())
+	if err != nil || authorization != "Basic Zm9vOmJhcg==" {
+		t.Fatalf("authorization should be basic, it got %s (%v)", authorization, err)
+	}
+}
+
+func TestBearer(t *testing.T) {
+	authorization, err := Bearer{Token: "token"}.Authorization(context.Background())
+	if err != nil || authorization != "Bearer token" {
+		t.Fatalf("authorization should be bearer, it got %s (%v)", authorization, err)
+	}
+}
+
+// decodeJWT returns the header, the claims and the signed content and signature of a token
+func decodeJWT(t *testing.T, token string) (map[string]interface{}, map[string]interface{}, []byte, []byte) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token should have 3 parts, it got %s", token)
+	}
+
+	segments := [][]byte{}
+	for _, part := range parts {
+		segment, err := base64.RawURLEncoding.DecodeString(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments = append(segments, segment)
+	}
+
+	header := map[string]interface{}{}
+	claims := map[string]interface{}{}
+	if json.Unmarshal(segments[0], &header) != nil || json.Unmarshal(segments[1], &claims) != nil {
+		t.Fatalf("token should have JSON header and claims, it got %s", token)
+	}
+
+	return header, claims, []byte(parts[0] + "." + parts[1]), segments[2]
+}
+
+func writeKey(t *testing.T, key interface{}) string {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "key.pem")
+	err = os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestJWT_Secret(t *testing.T) {
+	authorization, err := JWT{
+		Secret:    "secret",
+		KeyID:     "key-1",
+		Claims:    map[string]interface{}{"sub": "user-1"},
+		ExpiresIn: time.Hour,
+	}.Authorization(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, claims, content, signature := decodeJWT(t, strings.TrimPrefix(authorization, "Bearer "))
+	if header["alg"] != "HS256" || header["kid"] != "key-1" {
+		t.Fatalf("header is not correct: %v", header)
+	}
+	if claims["sub"] != "user-1" || claims["exp"].(float64)-claims["iat"].(float64) != 3600 {
+		t.Fatalf("claims are not correct: %v", claims)
+	}
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(content)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		t.Fatal("signature is not valid")
+	}
+}
+
+func TestJWT_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := JWT{KeyFile: writeKey(t, key)}.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _, content, signature := decodeJWT(t, token)
+	digest := sha256.Sum256(content)
+	if header["alg"] != "RS256" || rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature) != nil {
+		t.Fatalf("token should be signed with RS256, it got %v", header)
+	}
+}
+
+func TestJWT_EC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := JWT{KeyFile: writeKey(t, key)}.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _, content, signature := decodeJWT(t, token)
+	digest := sha256.Sum256(content)
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:])
+	if header["alg"] != "ES256" || !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Fatalf("token should be signed with ES256, it got %v", header)
+	}
+}
+
+func TestJWT_ECCurves(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := JWT{KeyFile: writeKey(t, key)}.Token()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header, _, content, signature := decodeJWT(t, token)
+	digest := sha512.Sum384(content)
+	r := new(big.Int).SetBytes(signature[:48])
+	s := new(big.Int).SetBytes(signature[48:])
+	if header["alg"] != "ES384" || len(signature) != 96 || !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Fatalf("token should be signed with ES384, it got %v", header)
+	}
+
+	tests := []struct {
+		curve     elliptic.Curve
+		algorithm string
+	}{
+		{curve: elliptic.P384(), algorithm: "ES256"},
+		{curve: elliptic.P256(), algorithm: "ES512"},
+		{curve: elliptic.P521(), algorithm: "ES384"},
+	}
+
+	for _, tt := range tests {
+		key, err := ecdsa.GenerateKey(tt.curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = JWT{KeyFile: writeKey(t, key), Algorithm: tt.algorithm}.Token()
+		if err == nil || !strings.Contains(err.Error(), "can't be used with the "+tt.curve.Params().Name+" key") {
+			t.Fatalf("%s should not be used with %s, it got %v", tt.algorithm, tt.curve.Params().Name, err)
+		}
+	}
+}
+
+func TestJWT_Invalid(t *testing.T) {
+	tests := map[string]JWT{
+		"no key":            {},
+		"secret and key":    {Secret: "secret", KeyFile: "key.pem"},
+		"key not found":     {KeyFile: "testdata/unknown.pem"},
+		"invalid algorithm": {Secret: "secret", Algorithm: "RS256"},
+		"unknown algorithm": {Secret: "secret", Algorithm: "HS1"},
+	}
+
+	for name, jwt := range tests {
+		_, err := jwt.Token()
+		if err == nil {
+			t.Fatalf("%s: it should fail to create the token", name)
+		}
+	}
+}
+
+func TestOAuth2(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		clientID, clientSecret, _ := req.BasicAuth()
+		if clientID != "client" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+
+		if req.FormValue("grant_type") != "client_credentials" || req.FormValue("scope") != "posts:read posts:write" || req.FormValue("audience") != "api" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`))
+	}))
+	defer server.Close()
+
+	provider := &OAuth2{
+		TokenURL:     server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"posts:read", "posts:write"},
+		Params:       map[string][]string{"audience": {"api"}},
+	}
+
+	for i := 0; i < 3; i++ {
+		authorization, err := provider.Authorization(context.Background())
+		if err != nil || authorization != "Bearer token" {
+			t.Fatalf("authorization should be the token, it got %s (%v)", authorization, err)
+		}
+	}
+
+	if requests != 1 {
+		t.Fatalf("token should be requested once and cached, it was requested %d times", requests)
+	}
+
+	_, err := (&OAuth2{TokenURL: server.URL, ClientID: "client"}).Authorization(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "oauth2 token request failed with status 401") {
+		t.Fatalf("it should fail due to invalid client, it got %v", err)
+	}
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	// registers the hashes used to sign the tokens
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+)
+
+// hashes are the hashes of the algorithms by their size (eg: 256 is used by HS256, RS256 and ES256)
+var hashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+// curveAlgorithms are the algorithms of the EC keys by the bit size of their curve (eg: P-384 is used by ES384)
+var curveAlgorithms = map[int]string{
+	256: "ES256",
+	384: "ES384",
+	521: "ES512",
+}
+
+// JWT authenticates with a JSON Web Token signed with a secret or a private key, created for each call
+type JWT struct {
+	// Algorithm used to sign the token: HS256, HS384, HS512, RS256, RS384, RS512, ES256, ES384 or ES512
+	// default: HS256 with a Secret, RS256 with a RSA key and the algorithm of the curve of an EC key (eg: ES384 with P-384)
+	Algorithm string
+
+	// Secret used to sign the token with HMAC
+	Secret string
+
+	// KeyFile is a PEM file with the RSA or EC private key used to sign the token
+	// eg: testdata/key.pem
+	KeyFile string
+
+	// KeyID is set in the kid header of the token
+	KeyID string
+
+	// Claims of the token
+	// eg: map[string]interface{}{"sub": "user-1", "role": "admin"}
+	Claims map[string]interface{}
+
+	// ExpiresIn sets the exp claim of the token, iat is always set to when it's created
+	// eg: time.Hour
+	ExpiresIn time.Duration
+}
+
+// Authorization returns the bearer authorization header with a new token
+func (j JWT) Authorization(ctx context.Context) (string, error) {
+	token, err := j.Token()
+	if err != nil {
+		return "", err
+	}
+
+	return "Bearer " + token, nil
+}
+
+// Token creates and signs a new token
+func (j JWT) Token() (string, error) {
+	key, err := j.key()
+	if err != nil {
+		return "", err
+	}
+
+	algorithm, err := j.algorithm(key)
+	if err != nil {
+		return "", err
+	}
+
+	header := map[string]interface{}{"alg": algorithm, "typ": "JWT"}
+	if j.KeyID != "" {
+		header["kid"] = j.KeyID
+	}
+
+	now := time.Now()
+	claims := map[string]interface{}{"iat": now.Unix()}
+	if j.ExpiresIn != 0 {
+		claims["exp"] = now.Add(j.ExpiresIn).Unix()
+	}
+	for name, value := range j.Claims {
+		claims[name] = value
+	}
+
+	encodedHeader, err := encodeSegment(header)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode jwt header: %w", err)
+	}
+
+	encodedClaims, err := encodeSegment(claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode jwt claims: %w", err)
+	}
+
+	unsigned := encodedHeader + "." + encodedClaims
+	signature, err := sign(algorithm, key, []byte(unsigned))
+	if err != nil {
+		return "", fmt.Errorf("failed to sign jwt: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// key returns the secret or the private key used to sign the token
+func (j JWT) key() (interface{}, error) {
+	if (j.Secret == "") == (j.KeyFile == "") {
+		return nil, errors.New("jwt should have either a secret or a key file")
+	}
+
+	if j.Secret != "" {
+		return []byte(j.Secret), nil
+	}
+
+	content, err := os.ReadFile(j.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt key file: %w", err)
+	}
+
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("jwt key file is not a PEM file")
+	}
+
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	return nil, errors.New("jwt key file should have a RSA or EC private key")
+}
+
+// algorithm returns the algorithm of the token, checking it can be used with the key
+func (j JWT) algorithm(key interface{}) (string, error) {
+	family := ""
+	switch key := key.(type) {
+	case []byte:
+		family = "HS"
+	case *rsa.PrivateKey:
+		family = "RS"
+	case *ecdsa.PrivateKey:
+		return j.curveAlgorithm(key)
+	default:
+		return "", fmt.Errorf("jwt key of type %T is not supported", key)
+	}
+
+	if j.Algorithm == "" {
+		return family + "256", nil
+	}
+
+	if len(j.Algorithm) != 5 || j.Algorithm[:2] != family || hashes[j.Algorithm[2:]] == 0 {
+		return "", fmt.Errorf("jwt algorithm '%s' can't be used with the key, it should be %s256, %s384 or %s512", j.Algorithm, family, family, family)
+	}
+
+	return j.Algorithm, nil
+}
+
+// curveAlgorithm returns the algorithm of the curve of an EC key, checking it's the algorithm set
+func (j JWT) curveAlgorithm(key *ecdsa.PrivateKey) (string, error) {
+	params := key.Curve.Params()
+
+	algorithm, ok := curveAlgorithms[params.BitSize]
+	if !ok {
+		return "", fmt.Errorf("jwt EC key with curve %s is not supported, it should be P-256, P-384 or P-521", params.Name)
+	}
+
+	if j.Algorithm != "" && j.Algorithm != algorithm {
+		return "", fmt.Errorf("jwt algorithm '%s' can't be used with the %s key, it should be %s", j.Algorithm, params.Name, algorithm)
+	}
+
+	return algorithm, nil
+}
+
+// sign returns the signature of the content with the algorithm
+func sign(algorithm string, key interface{}, content []byte) ([]byte, error) {
+	hash := hashes[algorithm[2:]]
+
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(hash.New, key)
+		mac.Write(content)
+		return mac.Sum(nil), nil
+	case *rsa.PrivateKey:
+		digest := hash.New()
+		digest.Write(content)
+		return rsa.SignPKCS1v15(rand.Reader, key, hash, digest.Sum(nil))
+	case *ecdsa.PrivateKey:
+		digest := hash.New()
+		digest.Write(content)
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		if err != nil {
+			return nil, err
+		}
+
+		// the signature is r and s with the size of the key each
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		r.FillBytes(signature[:size])
+		s.FillBytes(signature[size:])
+		return signature, nil
+	}
+
+	return nil, fmt.Errorf("jwt key of type %T is not supported", key)
+}
+
+func encodeSegment(value interface{}) (string, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(content), nil
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta is how long before its expiration a token is requested again
+const expiryDelta = 10 * time.Second
+
+// defaultClient requests the tokens if the Client is not set. It has its own transport,
+// so the tokens are still requested while the global httpmock transport replaces http.DefaultTransport.
+var defaultClient = &http.Client{Transport: defaultTransport()}
+
+// OAuth2 authenticates with a token requested with the OAuth2 client credentials grant.
+// The token is cached until it expires, so the provider should be shared by the calls (eg: &auth.OAuth2{}).
+type OAuth2 struct {
+	// TokenURL is the token endpoint of the authorization server
+	// eg: https://auth.example.com/oauth/token
+	TokenURL string
+
+	// ClientID and ClientSecret of the client, sent with basic authentication
+	ClientID     string
+	ClientSecret string
+
+	// Scopes requested
+	// eg: []string{"posts:read"}
+	Scopes []string
+
+	// Params are other params sent to the token endpoint
+	// eg: url.Values{"audience": []string{"https://api.example.com"}}
+	Params url.Values
+
+	// Client used to request the token
+	// default: a client with its own transport, which isn't replaced by the global httpmock transport
+	Client *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// tokenResponse is the response of the token endpoint
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Authorization returns the authorization header with the cached token, requesting a new one if it's expired
+func (o *OAuth2) Authorization(ctx context.Context) (string, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.token != "" && (o.expiry.IsZero() || time.Now().Before(o.expiry)) {
+		return o.token, nil
+	}
+
+	resp, err := o.requestToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	tokenType := resp.TokenType
+	if tokenType == "" || strings.EqualFold(tokenType, "bearer") {
+		tokenType = "Bearer"
+	}
+
+	o.token = tokenType + " " + resp.AccessToken
+	o.expiry = time.Time{}
+	if resp.ExpiresIn > 0 {
+		o.expiry = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - expiryDelta)
+	}
+
+	return o.token, nil
+}
+
+func (o *OAuth2) requestToken(ctx context.Context) (tokenResponse, error) {
+	params := url.Values{}
+	for key, values := range o.Params {
+		params[key] = values
+	}
+	params.Set("grant_type", "client_credentials")
+	if len(o.Scopes) > 0 {
+		params.Set("scope", strings.Join(o.Scopes, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.TokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to create oauth2 token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(o.ClientID), url.QueryEscape(o.ClientSecret))
+
+	client := o.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to request oauth2 token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to read oauth2 token response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tokenResponse{}, fmt.Errorf("oauth2 token request failed with status %d: %s", resp.StatusCode, body)
+	}
+
+	token := tokenResponse{}
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		return tokenResponse{}, fmt.Errorf("failed to parse oauth2 token response: %w", err)
+	}
+
+	if token.AccessToken == "" {
+		return tokenResponse{}, errors.New("oauth2 token response does not have an access token")
+	}
+
+	return token, nil
+}
+
+// defaultTransport returns a copy of http.DefaultTransport, or a transport with the same proxy if it was replaced
+func defaultTransport() http.RoundTripper {
+	transport, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	return transport.Clone()
+}
@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"context"
+	"net"
+	goHTTP "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lucasvmiguel/integration/assertion"
+	"github.com/lucasvmiguel/integration/auth"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+func TestAuth_HTTP(t *testing.T) {
+	err := Test(&HTTPTestCase{
+		Description: "TestAuth_HTTP",
+		Handler: goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+			w.Write([]byte(req.Header.Get("Authorization")))
+		}),
+		Request: call.Request{
+			URL:    "/",
+			Header: goHTTP.Header{"Authorization": []string{"Bearer other"}},
+			Auth:   auth.Basic{Username: "foo", Password: "bar"},
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       "Basic Zm9vOmJhcg==",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestAuth_HTTP invalid",
+		Handler:     goHTTP.NotFoundHandler(),
+		Request: call.Request{
+			URL:  "/",
+			Auth: auth.JWT{},
+		},
+		Response: expect.Response{StatusCode: goHTTP.StatusOK},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "failed to get authorization") {
+		t.Fatalf("it should fail due to the authorization, it got %v", result.Err())
+	}
+}
+
+func TestAuth_OAuth2WithGlobalHTTPAssertion(t *testing.T) {
+	tokenServer := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token": "token", "token_type": "bearer"}`))
+	}))
+	defer tokenServer.Close()
+
+	// the assertion has no mock, so the global httpmock transport replaces http.DefaultTransport
+	err := Test(&HTTPTestCase{
+		Description: "TestAuth_OAuth2WithGlobalHTTPAssertion",
+		Handler: goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+			resp, err := goHTTP.Get("https://jsonplaceholder.typicode.com/posts/1")
+			if err != nil {
+				goHTTP.Error(w, err.Error(), goHTTP.StatusInternalServerError)
+				return
+			}
+			resp.Body.Close()
+
+			w.Write([]byte(req.Header.Get("Authorization")))
+		}),
+		Request: call.Request{
+			URL:  "/",
+			Auth: &auth.OAuth2{TokenURL: tokenServer.URL, ClientID: "foo", ClientSecret: "bar"},
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       "Bearer token",
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{URL: "https://jsonplaceholder.typicode.com/posts/1"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuth_GRPC(t *testing.T) {
+	// the interceptor replies with the authorization received
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		return &chat.Message{Body: strings.Join(md.Get("authorization"), ", ")}, nil
+	}
+
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		chat.RegisterChatServiceServer(s, &Server{})
+	}, grpc.UnaryInterceptor(interceptor))
+
+	err := Test(&GRPCTestCase{
+		Description: "TestAuth_GRPC",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1},
+			Metadata:      metadata.Pairs("authorization", "Bearer other"),
+			Auth:          auth.Bearer{Token: "token"},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Body: "Bearer token"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuth_GRPCReflection(t *testing.T) {
+	// the server reflection is a stream, which is rejected without the authorization
+	streamInterceptor := func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, _ := metadata.FromIncomingContext(ss.Context())
+		if len(md.Get("authorization")) == 0 {
+			return status.Error(codes.Unauthenticated, "authorization is required")
+		}
+		return handler(srv, ss)
+	}
+	unaryInterceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		return &chat.Message{Body: strings.Join(md.Get("authorization"), ", ")}, nil
+	}
+
+	server := grpc.NewServer(grpc.StreamInterceptor(streamInterceptor), grpc.UnaryInterceptor(unaryInterceptor))
+	chat.RegisterChatServiceServer(server, &Server{})
+	reflection.Register(server)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	defer server.Stop()
+
+	err = Test(&GRPCTestCase{
+		Description: "TestAuth_GRPCReflection",
+		Call: call.Call{
+			Target:  listener.Addr().String(),
+			Method:  "chat.ChatService/SayHello",
+			Message: `{"id": 1}`,
+			Auth:    auth.Bearer{Token: "token"},
+		},
+		Output: expect.Output{
+			Message: `{"body": "Bearer token"}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuth_Websocket(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		_, _, err = c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(websocket.TextMessage, []byte(req.Header.Get("Authorization")))
+	}))
+	defer server.Close()
+
+	err := Test(&WebsocketTestCase{
+		Description: "TestAuth_Websocket",
+		Call: call.Websocket{
+			Scheme:  call.WebsocketSchemeWS,
+			URL:     strings.TrimPrefix(server.URL, "http://"),
+			Path:    "/",
+			Auth:    auth.Bearer{Token: "token"},
+			Message: "hello",
+		},
+		Receive: &expect.Message{
+			Content: "Bearer token",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package call
+
+import (
+	"net/http"
+)
+
+// ClientOptions sets up the HTTP client used to send the requests
+type ClientOptions struct {
+	// CAFile is a PEM file with the certificate authorities used to verify the server, besides the system ones
+	// eg: testdata/ca.pem
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate, sent to servers that require mTLS
+	// eg: testdata/client.pem
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify doesn't verify the server certificate, useful for self-signed certificates
+	InsecureSkipVerify bool
+	// NoRedirects returns the redirect responses instead of following them
+	NoRedirects bool
+	// MaxRedirects is the maximum number of redirects followed
+	// default: 10
+	MaxRedirects int
+	// Jar stores the cookies received and sends them with the next requests.
+	// A jar can be shared by test cases to keep a session.
+	// eg: cookiejar.New(nil)
+	Jar http.CookieJar
+	// Proxy is the URL of the proxy the requests are sent through
+	// default: the proxy of the environment (HTTP_PROXY, HTTPS_PROXY and NO_PROXY)
+	// eg: http://localhost:3128
+	Proxy string
+}
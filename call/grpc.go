@@ -1,5 +1,12 @@
 package call
 
+import (
+	"time"
+
+	"github.com/lucasvmiguel/integration/auth"
+	"google.golang.org/grpc/metadata"
+)
+
 // Call sets up how a GRPC request will be called
 type Call struct {
 	// GRPC service client used to call the server
@@ -10,7 +17,50 @@ type Call struct {
 	// eg: SayHello
 	Function string
 
-	// Message that will be sent with the request
-	// Eg: &chat.Message{Id: 1, Body: "Hello From the Server!"}
+	// Target is the address of the GRPC server called without a ServiceClient.
+	// Messages are sent and received as JSON, using the descriptors found with server reflection,
+	// ProtoFiles or DescriptorSet. The connection is not encrypted.
+	// eg: localhost:9000
+	Target string
+
+	// Method called on the Target, with the fully-qualified name of its service
+	// eg: chat.ChatService/SayHello
+	Method string
+
+	// ProtoFiles used to find the Method instead of server reflection
+	// eg: []string{"chat.proto"}
+	ProtoFiles []string
+
+	// ImportPaths where the ProtoFiles and their imports are looked up
+	// default: current directory
+	ImportPaths []string
+
+	// DescriptorSet is a file created by protoc --descriptor_set_out --include_imports,
+	// used to find the Method instead of server reflection
+	// eg: chat.protoset
+	DescriptorSet string
+
+	// Message that will be sent with the request.
+	// When the Target is called, it's a JSON string or a value that is marshaled to JSON.
+	// Eg: &chat.Message{Id: 1, Body: "Hello From the Server!"} or `{"id": 1, "body": "Hello From the Server!"}`
 	Message interface{}
+
+	// Messages that will be sent in order to a client streaming or bidirectional streaming function.
+	// The stream is half-closed after all messages are sent.
+	// If it's empty, Message is sent as the only message.
+	// Eg: []interface{}{&chat.Message{Id: 1}, &chat.Message{Id: 2}}
+	Messages []interface{}
+
+	// Metadata sent with the request
+	// eg: metadata.Pairs("authorization", "Bearer token")
+	Metadata metadata.MD
+
+	// Auth provides the authorization metadata of the request
+	// eg: auth.Bearer{Token: "token"}
+	Auth auth.Provider
+
+	// Timeout of the call, used as the deadline of its context.
+	// if nothing is set, there is no timeout.
+	// eg: 5 * time.Second
+	Timeout time.Duration
 }
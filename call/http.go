@@ -1,10 +1,16 @@
 package call
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+
+	"github.com/lucasvmiguel/integration/auth"
+)
 
 // Request sets up how a HTTP request will be called
 type Request struct {
-	// URL that will be called on the request
+	// URL that will be called on the request.
+	// It can be only a path if the request is served by a handler.
 	// eg: https://jsonplaceholder.typicode.com/todos
 	URL string
 	// Method that will be called on the request
@@ -14,7 +20,18 @@ type Request struct {
 	// a multiline string is valid
 	// eg: { "foo": "bar" }
 	Body string
+	// Payload builds the body of the request and sets its content type, it can't be set with Body.
+	// The content type set in Header is kept.
+	// eg: call.Form{"name": []string{"foo"}}
+	Payload Payload
 	// Header will be sent with the request
 	// eg: content-type=application/json
 	Header http.Header
+	// Auth provides the authorization header of the request, it overrides the one in Header
+	// eg: auth.Bearer{Token: "token"}
+	Auth auth.Provider
+	// Timeout of the request, including reading the response body.
+	// if nothing is set, there is no timeout.
+	// eg: 5 * time.Second
+	Timeout time.Duration
 }
@@ -0,0 +1,199 @@
+package call
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Payload builds the body of a request and its content type
+type Payload interface {
+	// Encode returns the body and its content type
+	Encode() ([]byte, string, error)
+}
+
+// Form is a payload with form fields, sent as application/x-www-form-urlencoded
+// eg: call.Form{"name": []string{"foo"}}
+type Form url.Values
+
+// Encode returns the form fields url encoded
+func (f Form) Encode() ([]byte, string, error) {
+	return []byte(url.Values(f).Encode()), "application/x-www-form-urlencoded", nil
+}
+
+// Multipart is a payload with form fields and files, sent as multipart/form-data
+type Multipart struct {
+	// Fields of the form
+	// eg: url.Values{"name": []string{"foo"}}
+	Fields url.Values
+	// Files of the form
+	Files []File
+}
+
+// File is a file of a multipart payload, with the content of the file in Path or Content
+type File struct {
+	// Field of the form the file is sent in
+	// eg: avatar
+	Field string
+	// Name of the file, required if Path is empty
+	// default: the name of the file in Path
+	// eg: avatar.png
+	Name string
+	// Path of the file read from disk
+	// eg: testdata/avatar.png
+	Path string
+	// Content of the file, used instead of reading it from Path
+	Content []byte
+	// ContentType of the file
+	// default: application/octet-stream
+	ContentType string
+}
+
+// Encode returns the multipart form with a random boundary
+func (m Multipart) Encode() ([]byte, string, error) {
+	body := bytes.Buffer{}
+	writer := multipart.NewWriter(&body)
+
+	// the fields are sorted, so the body is the same for the same payload apart from the boundary
+	keys := []string{}
+	for key := range m.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range m.Fields[key] {
+			err := writer.WriteField(key, value)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to write multipart field '%s': %w", key, err)
+			}
+		}
+	}
+
+	for _, file := range m.Files {
+		err := file.write(writer)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to write multipart: %w", err)
+	}
+
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+func (f File) write(writer *multipart.Writer) error {
+	if f.Field == "" {
+		return errors.New("multipart file field is required")
+	}
+
+	if f.Name == "" && f.Path == "" {
+		return fmt.Errorf("multipart file '%s' name is required if its path is empty", f.Field)
+	}
+
+	content, err := readContent(f.Path, f.Content)
+	if err != nil {
+		return err
+	}
+
+	name := f.Name
+	if name == "" {
+		name = filepath.Base(f.Path)
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(f.Field), escapeQuotes(name)))
+	header.Set("Content-Type", contentType(f.ContentType))
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to write multipart file '%s': %w", f.Field, err)
+	}
+
+	_, err = part.Write(content)
+	if err != nil {
+		return fmt.Errorf("failed to write multipart file '%s': %w", f.Field, err)
+	}
+
+	return nil
+}
+
+// Binary is a payload with raw bytes, with the content of the file in Path or Content
+type Binary struct {
+	// Path of the file read from disk
+	// eg: testdata/avatar.png
+	Path string
+	// Content sent, used instead of reading it from Path
+	Content []byte
+	// ContentType of the content
+	// default: application/octet-stream
+	ContentType string
+}
+
+// Encode returns the bytes of the file or the content
+func (b Binary) Encode() ([]byte, string, error) {
+	content, err := readContent(b.Path, b.Content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, contentType(b.ContentType), nil
+}
+
+// JSON is a payload with a value marshaled to JSON, sent as application/json
+// eg: call.JSON{Value: map[string]string{"title": "foo"}}
+type JSON struct {
+	Value interface{}
+}
+
+// Encode returns the value marshaled to JSON
+func (j JSON) Encode() ([]byte, string, error) {
+	content, err := json.Marshal(j.Value)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to marshal json payload: %w", err)
+	}
+
+	return content, "application/json", nil
+}
+
+// readContent returns the content, or the content of the file in the path if it's not set
+func readContent(path string, content []byte) ([]byte, error) {
+	if content != nil {
+		return content, nil
+	}
+
+	if path == "" {
+		return nil, errors.New("path or content is required")
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return content, nil
+}
+
+func contentType(value string) string {
+	if value == "" {
+		return "application/octet-stream"
+	}
+	return value
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
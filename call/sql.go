@@ -1,5 +1,7 @@
 package call
 
+import "time"
+
 // Query sets up how a SQL query will be called
 type Query struct {
 	// Statement that will be queried.
@@ -7,4 +9,8 @@ type Query struct {
 	Statement string
 	// Params that can be passed to the SQL query
 	Params []any
+	// Timeout of the query.
+	// if nothing is set, there is no timeout.
+	// eg: 5 * time.Second
+	Timeout time.Duration
 }
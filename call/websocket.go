@@ -2,7 +2,9 @@ package call
 
 import (
 	"net/http"
+	"time"
 
+	"github.com/lucasvmiguel/integration/auth"
 	"github.com/lucasvmiguel/integration/ws"
 )
 
@@ -32,6 +34,10 @@ type Websocket struct {
 	// eg: content-type=application/json
 	Header http.Header
 
+	// Auth provides the authorization header used to connect to the Websocket server
+	// eg: auth.Bearer{Token: "token"}
+	Auth auth.Provider
+
 	// Connection is the Websocket connection that will be used to make the calls (this field is optional).
 	// If you want to reuse a connection, you can set it here.
 	// If you set a connection, the `URL`, `Path`, `Header` and `Scheme` will be ignored.
@@ -49,4 +55,9 @@ type Websocket struct {
 
 	// CloseConnectionAfterCall will close the connection after the call is made.
 	CloseConnectionAfterCall bool
+
+	// Timeout of the call, including connecting to the server and receiving the message.
+	// if nothing is set, there is no timeout, but the message is still received within `Receive.Timeout`.
+	// eg: 5 * time.Second
+	Timeout time.Duration
 }
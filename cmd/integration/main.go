@@ -0,0 +1,275 @@
+// Command integration runs test suites written in YAML or JSON files.
+//
+// Usage:
+//
+//	integration [-var name=value]... [-timeout duration] [-junit file] [-json file] [-tap file] [-color] <file or directory>...
+//
+// Directories are searched recursively for .yaml, .yml and .json files with a list of cases,
+// other files (eg: OpenAPI specs and JSON schemas) are skipped.
+// Each suite is reported as a scenario in the JUnit XML, JSON and TAP files, if set.
+// The coverage of the contract of a suite, if it has one, is written below its result.
+// The diffs of the failures are colored if -color is set, by default when the output is a terminal and NO_COLOR is not set.
+// It exits with status 1 if any suite fails and 2 if it's used incorrectly.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lucasvmiguel/integration"
+	"github.com/lucasvmiguel/integration/contract"
+	"github.com/lucasvmiguel/integration/report"
+	"github.com/lucasvmiguel/integration/suite"
+	"gopkg.in/yaml.v3"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// vars is a flag that can be set many times with name=value
+type vars map[string]string
+
+func (v vars) String() string {
+	pairs := []string{}
+	for name, value := range v {
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v vars) Set(pair string) error {
+	name, value, ok := strings.Cut(pair, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("variable '%s' must be name=value", pair)
+	}
+	v[name] = value
+	return nil
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("integration", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: integration [-var name=value]... [-timeout duration] [-junit file] [-json file] [-tap file] [-color] <file or directory>...")
+		flags.PrintDefaults()
+	}
+
+	variables := vars{}
+	flags.Var(variables, "var", "variable available to all suites, overriding the suite variables (eg: -var baseURL=http://localhost:8080)")
+
+	junitPath := flags.String("junit", "", "file the JUnit XML report will be written to")
+	jsonPath := flags.String("json", "", "file the JSON report will be written to")
+	tapPath := flags.String("tap", "", "file the TAP report will be written to")
+	timeout := flags.Duration("timeout", 0, "timeout of each suite, 0 means no timeout (eg: -timeout 30s)")
+	color := flags.Bool("color", terminal(stdout), "color the diffs of the failures with ANSI codes, set by default if the output is a terminal")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	integration.SetDiffColor(*color)
+
+	files, err := suiteFiles(flags.Args())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	reporters := []fileReporter{}
+	for _, r := range []fileReporter{
+		{reporter: report.NewJUnit(), path: *junitPath},
+		{reporter: report.NewJSON(), path: *jsonPath},
+		{reporter: report.NewTAP(), path: *tapPath},
+	} {
+		if r.path != "" {
+			reporters = append(reporters, r)
+		}
+	}
+
+	for _, r := range reporters {
+		remove := integration.AddReporter(r)
+		defer remove()
+	}
+
+	failed := 0
+	for _, file := range files {
+		start := time.Now()
+
+		s, err := suite.Load(file)
+		if err == nil {
+			if s.Description == "" {
+				s.Description = file
+			}
+			err = runSuite(s, variables, *timeout)
+		} else {
+			// suites that can't be loaded never run, so they are reported here
+			result := &integration.Result{
+				Description: file,
+				Protocol:    "Scenario",
+				Duration:    time.Since(start),
+				Failures:    []integration.Failure{{Phase: integration.PhaseValidate, Message: "failed to load suite", Err: err}},
+			}
+			for _, r := range reporters {
+				r.Report(result)
+			}
+		}
+
+		if err != nil {
+			failed++
+			fmt.Fprintf(stdout, "FAIL\t%s\t%s\n\t%s\n", file, time.Since(start).Round(time.Millisecond), strings.ReplaceAll(err.Error(), "\n", "\n\t"))
+		} else {
+			fmt.Fprintf(stdout, "ok\t%s\t%s\n", file, time.Since(start).Round(time.Millisecond))
+		}
+
+		if s != nil {
+			writeCoverage(stdout, s.Coverage())
+		}
+	}
+
+	for _, r := range reporters {
+		err := r.writeFile()
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(stdout, "FAIL\t%d of %d suites failed\n", failed, len(files))
+		return 1
+	}
+
+	fmt.Fprintf(stdout, "PASS\t%d suites\n", len(files))
+	return 0
+}
+
+func runSuite(s *suite.Suite, vars map[string]string, timeout time.Duration) error {
+	if timeout == 0 {
+		return s.Run(vars)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return s.RunContext(ctx, vars)
+}
+
+// terminal returns true if the output is a terminal and NO_COLOR is not set
+func terminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeCoverage writes the coverage of the contract of a suite, indented below its result
+func writeCoverage(w io.Writer, coverage *contract.Coverage) {
+	if coverage == nil {
+		return
+	}
+
+	for _, line := range strings.Split(strings.TrimSuffix(coverage.String(), "\n"), "\n") {
+		fmt.Fprintf(w, "\t%s\n", line)
+	}
+}
+
+// reporter is implemented by the reporters of the report package
+type reporter interface {
+	integration.Reporter
+	Write(w io.Writer) error
+}
+
+// fileReporter writes a report to a file
+type fileReporter struct {
+	reporter
+	path string
+}
+
+func (r fileReporter) writeFile() error {
+	file, err := os.Create(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	defer file.Close()
+
+	err = r.Write(file)
+	if err != nil {
+		return fmt.Errorf("%s: %w", r.path, err)
+	}
+
+	return file.Close()
+}
+
+func suiteFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch filepath.Ext(file) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() && isSuite(file) {
+					files = append(files, file)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no suite files found in %s", strings.Join(paths, ", "))
+	}
+
+	return files, nil
+}
+
+// isSuite returns true if the file is a YAML or JSON object with cases, even if they are invalid,
+// so the files in the directories of the suites that aren't suites are skipped
+func isSuite(file string) bool {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+
+	var document struct {
+		Cases interface{} `yaml:"cases"`
+	}
+	err = yaml.Unmarshal(content, &document)
+	return err == nil && document.Cases != nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte("foo"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "foo"}`))
+	}))
+	defer server.Close()
+
+	stdout := &bytes.Buffer{}
+	code := run([]string{"-var", "baseURL=" + server.URL, "testdata/posts.json"}, stdout, &bytes.Buffer{})
+	if code != 0 {
+		t.Fatalf("exit code should be 0, it got %d: %s", code, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "PASS\t1 suites") {
+		t.Fatalf("output should report the suites passed, it got '%s'", stdout.String())
+	}
+}
+
+func TestRun_Failed(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	code := run([]string{"testdata"}, stdout, &bytes.Buffer{})
+	if code != 1 {
+		t.Fatalf("exit code should be 1, it got %d: %s", code, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "FAIL\t2 of 2 suites failed") {
+		t.Fatalf("output should report the suites failed, it got '%s'", stdout.String())
+	}
+}
+
+func TestRun_SkipsFilesThatAreNotSuites(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"invalid.yaml":     "description: invalid\ncases:\n  - description: case without http or websocket\n",
+		"openapi.yaml":     "openapi: 3.0.0\ninfo:\n  title: posts\n  version: 1.0.0\npaths: {}\n",
+		"post.schema.json": `{"type": "object", "required": ["id"]}`,
+		"list.json":        `[1, 2]`,
+	}
+	for name, content := range files {
+		err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stdout := &bytes.Buffer{}
+	code := run([]string{dir}, stdout, &bytes.Buffer{})
+	if code != 1 {
+		t.Fatalf("exit code should be 1, it got %d: %s", code, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "FAIL\t1 of 1 suites failed") {
+		t.Fatalf("output should only report the suite, it got '%s'", stdout.String())
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{{}, {"-var", "invalid"}, {"unknown.yaml"}} {
+		code := run(args, &bytes.Buffer{}, &bytes.Buffer{})
+		if code != 2 {
+			t.Fatalf("exit code should be 2 for %v, it got %d", args, code)
+		}
+	}
+}
+
+func TestRun_Reports(t *testing.T) {
+	dir := t.TempDir()
+	junit := filepath.Join(dir, "junit.xml")
+	tap := filepath.Join(dir, "report.tap")
+
+	stdout := &bytes.Buffer{}
+	code := run([]string{"-junit", junit, "-tap", tap, "testdata"}, stdout, &bytes.Buffer{})
+	if code != 1 {
+		t.Fatalf("exit code should be 1, it got %d: %s", code, stdout.String())
+	}
+
+	content, err := os.ReadFile(junit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), `<testsuite name="testdata/invalid.yaml"`) {
+		t.Fatalf("JUnit report should have the invalid suite, it got '%s'", content)
+	}
+
+	content, err = os.ReadFile(tap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(content), "1..2\nnot ok 1 - testdata/invalid.yaml\n") {
+		t.Fatalf("TAP report should have the suites, it got '%s'", content)
+	}
+}
+
+func TestRun_Coverage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "foo"}`))
+	}))
+	defer server.Close()
+
+	contract, err := filepath.Abs("../../suite/testdata/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := filepath.Join(t.TempDir(), "posts.yaml")
+	err = os.WriteFile(file, []byte(`
+contract: `+contract+`
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+      response:
+        status: 200
+        body: { id: 1, title: foo }
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	code := run([]string{"-var", "baseURL=" + server.URL, file}, stdout, &bytes.Buffer{})
+	if code != 0 {
+		t.Fatalf("exit code should be 0, it got %d: %s", code, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "\tcontract coverage: 1 of 2 operations (50.0%)\n\tPOST  /posts       createPost  not covered\n\tGET   /posts/{id}  getPost     1 call\n") {
+		t.Fatalf("output should have the contract coverage, it got '%s'", stdout.String())
+	}
+}
+
+func TestRun_Diff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "bar"}`))
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "posts.yaml")
+	err := os.WriteFile(file, []byte(`
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+      response:
+        status: 200
+        body: { id: 1, title: foo }
+`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout := &bytes.Buffer{}
+	code := run([]string{"-var", "baseURL=" + server.URL, file}, stdout, &bytes.Buffer{})
+	if code != 1 {
+		t.Fatalf("exit code should be 1, it got %d: %s", code, stdout.String())
+	}
+
+	if !strings.Contains(stdout.String(), "\t\t--- expected\n\t\t+++ actual\n\t\t@@ $.title @@\n\t\t-\"foo\"\n\t\t+\"bar\"\n") {
+		t.Fatalf("output should have the diff indented, it got '%s'", stdout.String())
+	}
+
+	stdout = &bytes.Buffer{}
+	run([]string{"-color", "-var", "baseURL=" + server.URL, file}, stdout, &bytes.Buffer{})
+	if !strings.Contains(stdout.String(), "\x1b[31m-\"foo\"\x1b[0m") {
+		t.Fatalf("output should have the diff colored, it got '%s'", stdout.String())
+	}
+}
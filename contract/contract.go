@@ -0,0 +1,206 @@
+// Package contract validates HTTP requests and responses against an OpenAPI 3 spec
+// and reports which of its operations were exercised.
+package contract
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// Spec is an OpenAPI 3 spec the requests and responses are validated against.
+// It should be loaded once and shared by the test cases, so it records the operations of all of them.
+type Spec struct {
+	document *openapi3.T
+	router   routers.Router
+
+	mutex sync.Mutex
+	// calls are the number of calls by operation (eg: GET /posts/{id})
+	calls map[string]int
+}
+
+// Load loads the spec of a YAML or JSON file, where the external $ref are looked up
+// eg: contract.Load("openapi.yaml")
+func Load(path string) (*Spec, error) {
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	document, err := loader.LoadFromFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contract: %w", err)
+	}
+
+	return newSpec(loader, document)
+}
+
+// Parse parses the spec of a YAML or JSON content
+func Parse(content []byte) (*Spec, error) {
+	loader := openapi3.NewLoader()
+
+	document, err := loader.LoadFromData(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse contract: %w", err)
+	}
+
+	return newSpec(loader, document)
+}
+
+func newSpec(loader *openapi3.Loader, document *openapi3.T) (*Spec, error) {
+	err := document.Validate(loader.Context)
+	if err != nil {
+		return nil, fmt.Errorf("contract is invalid: %w", err)
+	}
+
+	// the servers are matched by their path only, so requests sent to any host (eg: localhost) are validated
+	document.Servers, err = basePaths(document.Servers)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range document.Paths {
+		item.Servers, err = basePaths(item.Servers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	router, err := gorillamux.NewRouter(document)
+	if err != nil {
+		return nil, fmt.Errorf("failed to route contract: %w", err)
+	}
+
+	return &Spec{document: document, router: router, calls: map[string]int{}}, nil
+}
+
+// basePaths returns servers with only the path of the servers
+// eg: https://api.example.com/v1 is /v1
+func basePaths(servers openapi3.Servers) (openapi3.Servers, error) {
+	paths := openapi3.Servers{}
+	found := map[string]bool{}
+	for _, server := range servers {
+		path, err := server.BasePath()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract server '%s': %w", server.URL, err)
+		}
+
+		if !found[path] {
+			found[path] = true
+			paths = append(paths, &openapi3.Server{URL: path})
+		}
+	}
+	return paths, nil
+}
+
+// Validate returns the violations of the spec by the request sent and the response received,
+// and records the call of their operation.
+// The body of the request is read with GetBody, since the client has already read it.
+// The status, the header and the content type of the response have to be declared by the operation,
+// and the bodies have to match their schemas.
+func (s *Spec) Validate(ctx context.Context, req *http.Request, resp *http.Response, respBody []byte) []error {
+	route, params, err := s.router.FindRoute(req)
+	if err != nil {
+		return []error{fmt.Errorf("operation %s %s is not in the contract", req.Method, req.URL.Path)}
+	}
+
+	operation := operationKey(route.Method, route.Path)
+	s.record(operation)
+
+	req, err = withBody(req)
+	if err != nil {
+		return []error{err}
+	}
+
+	options := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		SkipSettingDefaults:   true,
+		// the contract is validated, not the credentials
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	// the schema and the value are left out, so each violation fits in a line
+	options.WithCustomSchemaErrorFunc(func(err *openapi3.SchemaError) string {
+		return fmt.Sprintf("/%s: %s", strings.Join(err.JSONPointer(), "/"), err.Reason)
+	})
+
+	requestInput := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options:    options,
+	}
+
+	errs := []error{}
+
+	err = openapi3filter.ValidateRequest(ctx, requestInput)
+	for _, err := range violations(err) {
+		errs = append(errs, fmt.Errorf("request of %s does not match the contract: %w", operation, err))
+	}
+
+	err = openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 resp.StatusCode,
+		Header:                 resp.Header,
+		Body:                   io.NopCloser(bytes.NewReader(respBody)),
+		Options:                options,
+	})
+	for _, err := range violations(err) {
+		errs = append(errs, fmt.Errorf("response of %s does not match the contract: %w", operation, err))
+	}
+
+	return errs
+}
+
+// withBody returns a copy of the request with a body that can be read again
+func withBody(req *http.Request) (*http.Request, error) {
+	req = req.Clone(req.Context())
+	req.Body = http.NoBody
+
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		req.Body = body
+	}
+
+	return req, nil
+}
+
+// violations returns each error of a validation, or nil if it's valid
+func violations(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	// only the errors of the validation are split, the ones they wrap keep their context (eg: request body)
+	if multiErr, ok := err.(openapi3.MultiError); ok {
+		errs := []error{}
+		for _, err := range multiErr {
+			errs = append(errs, violations(err)...)
+		}
+		return errs
+	}
+
+	return []error{err}
+}
+
+func (s *Spec) record(operation string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.calls[operation]++
+}
+
+// operationKey identifies an operation by its method and path
+// eg: GET /posts/{id}
+func operationKey(method, path string) string {
+	return method + " " + path
+}
@@ -0,0 +1,147 @@
+package contract
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Posts
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /posts:
+    post:
+      operationId: createPost
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+      responses:
+        "201":
+          description: Created
+          headers:
+            Location:
+              required: true
+              schema:
+                type: string
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id]
+                properties:
+                  id:
+                    type: integer
+  /posts/{id}:
+    get:
+      operationId: getPost
+      parameters:
+        - { name: id, in: path, required: true, schema: { type: integer } }
+      responses:
+        "200":
+          description: OK
+`
+
+func response(status int, header http.Header) *http.Response {
+	return &http.Response{StatusCode: status, Header: header}
+}
+
+func TestValidate(t *testing.T) {
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonHeader := http.Header{"Content-Type": []string{"application/json"}}
+
+	tests := []struct {
+		name      string
+		resp      *http.Response
+		respBody  string
+		violation string
+	}{
+		{
+			name:     "valid",
+			resp:     response(http.StatusCreated, http.Header{"Content-Type": []string{"application/json"}, "Location": []string{"/v1/posts/1"}}),
+			respBody: `{"id": 1}`,
+		},
+		{
+			name:      "header",
+			resp:      response(http.StatusCreated, jsonHeader),
+			respBody:  `{"id": 1}`,
+			violation: `response header "Location" missing`,
+		},
+		{
+			name:      "content type",
+			resp:      response(http.StatusCreated, http.Header{"Content-Type": []string{"text/plain"}, "Location": []string{"/v1/posts/1"}}),
+			respBody:  `1`,
+			violation: `response header Content-Type has unexpected value: "text/plain"`,
+		},
+		{
+			name:      "body",
+			resp:      response(http.StatusCreated, http.Header{"Content-Type": []string{"application/json"}, "Location": []string{"/v1/posts/1"}}),
+			respBody:  `{"id": "1"}`,
+			violation: `/id: value must be an integer`,
+		},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost:8080/v1/posts", strings.NewReader(`{"title": "foo"}`))
+		req.Header = jsonHeader.Clone()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(`{"title": "foo"}`)), nil
+		}
+
+		errs := s.Validate(context.Background(), req, tt.resp, []byte(tt.respBody))
+		if tt.violation == "" && len(errs) > 0 {
+			t.Fatalf("%s: it should be valid, it got %v", tt.name, errs)
+		}
+		if tt.violation != "" && (len(errs) != 1 || !strings.Contains(errs[0].Error(), tt.violation)) {
+			t.Fatalf("%s: it should fail with '%s', it got %v", tt.name, tt.violation, errs)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	s, err := Parse([]byte(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/v1/posts/1", nil)
+		errs := s.Validate(context.Background(), req, response(http.StatusOK, http.Header{}), nil)
+		if len(errs) > 0 {
+			t.Fatal(errs)
+		}
+	}
+
+	expected := `contract coverage: 1 of 2 operations (50.0%)
+POST  /posts       createPost  not covered
+GET   /posts/{id}  getPost     2 calls
+`
+	if s.Coverage().String() != expected {
+		t.Fatalf("coverage should be\n%s\nit got\n%s", expected, s.Coverage())
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse([]byte(`openapi: 3.0.3`))
+	if err == nil || !strings.Contains(err.Error(), "contract is invalid") {
+		t.Fatalf("it should fail due to the missing info and paths, it got %v", err)
+	}
+
+	_, err = Load("testdata/not-found.yaml")
+	if err == nil {
+		t.Fatal("it should fail due to the missing file")
+	}
+}
@@ -0,0 +1,108 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// Coverage describes which operations of the spec were exercised
+type Coverage struct {
+	// Operations of the spec, sorted by path and method
+	Operations []Operation
+}
+
+// Operation is an operation of the spec and the number of calls it got
+type Operation struct {
+	// Method of the operation
+	// eg: GET
+	Method string
+	// Path of the operation
+	// eg: /posts/{id}
+	Path string
+	// ID of the operation, if the spec sets it
+	// eg: getPost
+	ID string
+	// Calls validated against the operation
+	Calls int
+}
+
+// Coverage returns the operations of the spec and how many times each one was called so far
+func (s *Spec) Coverage() Coverage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	paths := []string{}
+	for path := range s.document.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	coverage := Coverage{}
+	for _, path := range paths {
+		operations := s.document.Paths[path].Operations()
+
+		methods := []string{}
+		for method := range operations {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			coverage.Operations = append(coverage.Operations, Operation{
+				Method: method,
+				Path:   path,
+				ID:     operations[method].OperationID,
+				Calls:  s.calls[operationKey(method, path)],
+			})
+		}
+	}
+
+	return coverage
+}
+
+// Covered returns the number of operations called at least once
+func (c Coverage) Covered() int {
+	covered := 0
+	for _, operation := range c.Operations {
+		if operation.Calls > 0 {
+			covered++
+		}
+	}
+	return covered
+}
+
+// Percentage returns the percentage of operations called at least once
+func (c Coverage) Percentage() float64 {
+	if len(c.Operations) == 0 {
+		return 0
+	}
+	return float64(c.Covered()) * 100 / float64(len(c.Operations))
+}
+
+// String returns the coverage as a text report, with an operation per line
+// eg:
+//
+//	contract coverage: 1 of 2 operations (50.0%)
+//	GET   /posts/{id}  getPost     2 calls
+//	POST  /posts       createPost  not covered
+func (c Coverage) String() string {
+	builder := strings.Builder{}
+	fmt.Fprintf(&builder, "contract coverage: %d of %d operations (%.1f%%)\n", c.Covered(), len(c.Operations), c.Percentage())
+
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	for _, operation := range c.Operations {
+		calls := "not covered"
+		switch {
+		case operation.Calls == 1:
+			calls = "1 call"
+		case operation.Calls > 1:
+			calls = fmt.Sprintf("%d calls", operation.Calls)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", operation.Method, operation.Path, operation.ID, calls)
+	}
+	writer.Flush()
+
+	return builder.String()
+}
@@ -0,0 +1,46 @@
+package integration
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lucasvmiguel/integration/internal/diff"
+	"github.com/lucasvmiguel/integration/internal/utils"
+)
+
+// diffColor is true if the diffs in the errors have ANSI colors
+var diffColor atomic.Bool
+
+// SetDiffColor sets whether the diffs in the errors of Test and Result.Err have ANSI colors,
+// eg: when they are written to a terminal. The diffs in the failures of the result never have colors.
+// default: false
+func SetDiffColor(enabled bool) {
+	diffColor.Store(enabled)
+}
+
+// bodyDiff returns a unified diff of the actual body from the expected one,
+// with a hunk for each path that differs if both are JSON, or for each group of lines that differ otherwise.
+// Texts in a single line have no diff, since their error already shows both.
+func bodyDiff(expected, actual string) string {
+	if utils.IsJSON(expected) && json.Valid([]byte(actual)) {
+		return diff.JSON(expected, actual)
+	}
+
+	if !strings.Contains(expected, "\n") && !strings.Contains(actual, "\n") {
+		return ""
+	}
+	return diff.Text(expected, actual)
+}
+
+// withDiff returns the message followed by the diff indented, colored if it's enabled
+func withDiff(message, d string) string {
+	if d == "" {
+		return message
+	}
+
+	if diffColor.Load() {
+		d = diff.Color(d)
+	}
+	return message + "\n\t" + strings.ReplaceAll(strings.TrimSuffix(d, "\n"), "\n", "\n\t")
+}
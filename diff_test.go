@@ -0,0 +1,117 @@
+package integration
+
+import (
+	"context"
+	goHTTP "net/http"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+)
+
+func TestDiff_JSON(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestDiff_JSON",
+		Handler:     bodyHandler(`{"id": 1, "title": "bar", "tags": ["a"], "views": 10}`),
+		Request:     call.Request{URL: "/posts/1"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       `{"id": "<<ANY_NUMBER>>", "title": "foo", "tags": ["a", "b"]}`,
+		},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail due to the body")
+	}
+
+	diff := "--- expected\n+++ actual\n@@ $.tags[1] @@\n-\"b\"\n@@ $.title @@\n-\"foo\"\n+\"bar\"\n@@ $.views @@\n+10\n"
+	if result.Failures[0].Diff != diff {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", diff, result.Failures[0].Diff)
+	}
+
+	if !strings.Contains(result.Err().Error(), "\n\t@@ $.title @@\n\t-\"foo\"\n\t+\"bar\"\n") {
+		t.Fatalf("error should contain the diff, it got %v", result.Err())
+	}
+}
+
+func TestDiff_Text(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestDiff_Text",
+		Handler:     bodyHandler("first\nsecond\nthird"),
+		Request:     call.Request{URL: "/"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: "first\nthird\nfourth"},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail due to the body")
+	}
+
+	diff := "--- expected\n+++ actual\n@@ -1,3 +1,3 @@\n first\n+second\n third\n-fourth\n"
+	if result.Failures[0].Diff != diff {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", diff, result.Failures[0].Diff)
+	}
+
+	result = Evaluate(&HTTPTestCase{
+		Description: "TestDiff_Text single line",
+		Handler:     bodyHandler("foo"),
+		Request:     call.Request{URL: "/"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: "bar"},
+	})
+	if result.Passed() || result.Failures[0].Diff != "" {
+		t.Fatalf("it should fail without a diff, it got %v", result.Err())
+	}
+}
+
+func TestDiff_Color(t *testing.T) {
+	SetDiffColor(true)
+	defer SetDiffColor(false)
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestDiff_Color",
+		Handler:     bodyHandler(`{"title": "bar"}`),
+		Request:     call.Request{URL: "/posts/1"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: `{"title": "foo"}`},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail due to the body")
+	}
+
+	if !strings.Contains(result.Err().Error(), "\t\x1b[31m-\"foo\"\x1b[0m\n\t\x1b[32m+\"bar\"\x1b[0m") {
+		t.Fatalf("error should contain the colored diff, it got %q", result.Err())
+	}
+
+	if strings.Contains(result.Failures[0].Diff, "\x1b[") {
+		t.Fatalf("diff of the failure should not be colored, it got %q", result.Failures[0].Diff)
+	}
+}
+
+func TestDiff_GRPC(t *testing.T) {
+	// the interceptor replies with the body of the message received
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return &chat.Message{Id: req.(*chat.Message).Id, Body: req.(*chat.Message).Body}, nil
+	}
+
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		chat.RegisterChatServiceServer(s, &Server{})
+	}, grpc.UnaryInterceptor(interceptor))
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestDiff_GRPC",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "bar"},
+		},
+		Output: expect.Output{Message: &chat.Message{Id: 2, Body: "foo"}},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail due to the message")
+	}
+
+	diff := "--- expected\n+++ actual\n@@ $.body @@\n-\"foo\"\n+\"bar\"\n@@ $.id @@\n-2\n+1\n"
+	if result.Failures[0].Diff != diff {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", diff, result.Failures[0].Diff)
+	}
+}
@@ -1,14 +1,80 @@
 package expect
 
-import "google.golang.org/grpc/status"
+import (
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
 
 // Output is used to validate if a GRPC response was returned with the correct parameters
 type Output struct {
-	// Message expected in the GRPC response
+	// Message expected in the GRPC response, compared with its protojson form.
+	// A string is compared as JSON.
 	// Eg: &chat.Message{Id: 1, Body: "Hello From the Server!", Comment: "<<PRESENCE>>"}
 	Message interface{}
 
-	// Error expected in the GRPC response
+	// Messages expected in the stream of a server streaming or bidirectional streaming function, in order
+	// Eg: []interface{}{&chat.Message{Id: 1}, &chat.Message{Id: 2}}
+	Messages []interface{}
+
+	// Unordered allows the Messages to be received in any order
+	Unordered bool
+
+	// Exact compares the messages with proto.Equal instead of their JSON, so <<PRESENCE>> can't be used.
+	// An expected message that is not of the type received is converted from its JSON.
+	Exact bool
+
+	// IgnoreFields are field mask paths of fields that are not compared
+	// eg: []string{"comment", "author.created_at"}
+	IgnoreFields []string
+
+	// Schema the protojson of the messages received is validated against.
+	// If Message and Messages are empty, the messages are only validated by the schema.
+	// eg: &expect.Schema{File: "testdata/message.schema.json"}
+	Schema *Schema
+
+	// Header expected in the GRPC response.
+	// Every key set in here will be asserted, others will be ignored.
+	// eg: metadata.Pairs("x-request-id", "123")
+	Header metadata.MD
+
+	// Trailer expected in the GRPC response.
+	// Every key set in here will be asserted, others will be ignored.
+	// eg: metadata.Pairs("x-server", "chat")
+	Trailer metadata.MD
+
+	// Error expected in the GRPC response. For streams, it's the status the stream ended with.
 	// Eg: status.New(codes.Unavailable, "error message"),
 	Err *status.Status
+
+	// Status expected in the GRPC response, which can't be set with Err.
+	// Unlike Err, it can match part of the message and the details of the status.
+	// Eg: &expect.Status{Code: codes.InvalidArgument, MessageContains: "invalid"}
+	Status *Status
+}
+
+// Status is used to validate the status of a GRPC response that failed
+type Status struct {
+	// Code expected in the status
+	// Eg: codes.InvalidArgument
+	Code codes.Code
+
+	// Message expected in the status.
+	// If Message, MessageContains and MessageRegex are empty, the message is not asserted.
+	// Eg: title is required
+	Message string
+
+	// MessageContains is a text the message is expected to contain
+	// Eg: required
+	MessageContains string
+
+	// MessageRegex is a regular expression the message is expected to match
+	// Eg: ^(title|body) is required$
+	MessageRegex string
+
+	// Details expected in the status, in any order. Details received that are not set in here are ignored.
+	// A detail is a proto message, compared with the detail of the same type as JSON (<<PRESENCE>> is supported),
+	// or a JSON string with its type in the "@type" field.
+	// Eg: []interface{}{&errdetails.ErrorInfo{Reason: "INVALID_TITLE", Domain: "<<PRESENCE>>"}}
+	Details []interface{}
 }
@@ -1,6 +1,9 @@
 package expect
 
-import "net/http"
+import (
+	"net/http"
+	"time"
+)
 
 // Request struct is used to validate if a HTTP request was made with the correct parameters
 type Request struct {
@@ -30,4 +33,81 @@ type Response struct {
 	// Every header set in here will be asserted, others will be ignored.
 	// eg: content-type=application/json
 	Header http.Header
+	// Cookies expected to be set in the HTTP response (Set-Cookie header).
+	// Every cookie set in here will be asserted, others will be ignored.
+	Cookies []Cookie
+	// Fields of the JSON response body asserted by their path.
+	// If Body is empty, only the fields are asserted.
+	// eg: []expect.Field{{Path: "items", Operator: expect.OperatorLength, Value: 20}}
+	Fields []Field
+	// Schema the JSON response body is validated against.
+	// If Body is empty, the body is only validated by the schema.
+	// eg: &expect.Schema{File: "testdata/post.schema.json"}
+	Schema *Schema
+}
+
+// Operator compares a field of a JSON body with the expected value
+type Operator string
+
+const (
+	// OperatorEquals expects the field to be equal to the value
+	OperatorEquals Operator = "equals"
+	// OperatorNotEquals expects the field to be different from the value
+	OperatorNotEquals Operator = "notEquals"
+	// OperatorExists expects the field to exist, the value is not used
+	OperatorExists Operator = "exists"
+	// OperatorAbsent expects the field to not exist, the value is not used
+	OperatorAbsent Operator = "absent"
+	// OperatorLength expects the list, object or string to have the length of the value
+	OperatorLength Operator = "length"
+	// OperatorGreaterThan expects the number to be greater than the value
+	OperatorGreaterThan Operator = "greaterThan"
+	// OperatorLessThan expects the number to be less than the value
+	OperatorLessThan Operator = "lessThan"
+	// OperatorRegex expects the field to match the regular expression of the value
+	OperatorRegex Operator = "regex"
+	// OperatorContains expects the string to contain the value, the list to have the value as an item
+	// or the object to have the value as a key
+	OperatorContains Operator = "contains"
+	// OperatorOneOf expects the field to be equal to one of the items of the value, which is a list
+	OperatorOneOf Operator = "oneOf"
+)
+
+// Field is used to validate a value of a JSON body found by its path
+type Field struct {
+	// Path of the field, with keys separated by dots and array indexes in brackets
+	// eg: $.items[0].price
+	Path string
+	// Operator used to compare the field with the value
+	// default: equals
+	Operator Operator
+	// Value the field is compared with. It's compared as JSON, so numbers of any type can be used.
+	// eg: 20
+	Value interface{}
+}
+
+// Cookie is used to validate if a cookie was set with the correct attributes.
+// Only the attributes set are asserted.
+type Cookie struct {
+	// Name of the cookie
+	Name string
+	// Value expected in the cookie
+	Value string
+	// ValueRegex is a regular expression the cookie value should match
+	// eg: ^[a-f0-9]{32}$
+	ValueRegex string
+	// Path expected in the cookie
+	Path string
+	// Domain expected in the cookie
+	Domain string
+	// HttpOnly expects the cookie to be HttpOnly
+	HttpOnly bool
+	// Secure expects the cookie to be Secure
+	Secure bool
+	// SameSite expected in the cookie: Strict, Lax or None
+	SameSite string
+	// ExpiresIn is when the cookie is expected to expire, by its Max-Age or Expires attributes.
+	// A difference of a few seconds is accepted.
+	// eg: 24 * time.Hour
+	ExpiresIn time.Duration
 }
@@ -0,0 +1,13 @@
+package expect
+
+// Schema is a JSON Schema a JSON is validated against, loaded from a file or a string.
+// Draft 2020-12 is used if the schema doesn't set its $schema.
+type Schema struct {
+	// File is the path of the schema, where the relative $ref are looked up
+	// eg: testdata/post.schema.json
+	File string
+
+	// Content of the schema, used instead of a File
+	// eg: { "type": "object", "required": ["id"] }
+	Content string
+}
@@ -9,6 +9,11 @@ type Message struct {
 	// eg: { "foo": "bar" }
 	Content string
 
+	// Schema the JSON content of the message is validated against.
+	// If Content is empty, the message is only validated by the schema.
+	// eg: &expect.Schema{File: "testdata/post.schema.json"}
+	Schema *Schema
+
 	// Timeout is the time to wait for a message to be received.
 	Timeout time.Duration
 }
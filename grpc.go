@@ -5,14 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/jarcoal/httpmock"
-	"github.com/kinbiko/jsonassert"
 	"github.com/lucasvmiguel/integration/assertion"
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
-	"github.com/lucasvmiguel/integration/internal/utils"
+	"github.com/lucasvmiguel/integration/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -30,131 +36,662 @@ type GRPCTestCase struct {
 
 	// Assertions that will run in test case
 	Assertions []assertion.Assertion
+
+	// Mock is the registry used by the HTTP assertions of the test case (eg: mock.NewTransport()).
+	// If it's nil, the HTTP assertions use the global httpmock transport, which can't run in parallel.
+	Mock mock.Registry
+}
+
+// streamKind is how a GRPC function sends and receives messages
+type streamKind int
+
+const (
+	unary streamKind = iota
+	serverStreaming
+	clientStreaming
+	bidiStreaming
+)
+
+// grpcResponse is what a GRPC function returned.
+// A stream returns messages, other functions return a single message.
+type grpcResponse struct {
+	message  interface{}
+	messages []interface{}
+	err      error
+	stream   bool
+	header   metadata.MD
+	trailer  metadata.MD
 }
 
 // Test runs an GRPC test case
 func (t *GRPCTestCase) Test() error {
+	return t.TestContext(context.Background())
+}
+
+// TestContext runs an GRPC test case, the context is passed to the GRPC function
+func (t *GRPCTestCase) TestContext(ctx context.Context) error {
+	return t.evaluate(ctx).Err()
+}
+
+func (t *GRPCTestCase) evaluate(ctx context.Context) *Result {
+	result := newResult(t.Description, "GRPC")
+	defer result.finish()
+
 	err := t.validate()
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to validate test case"))
+		return result.fail(PhaseValidate, "failed to validate test case", err)
 	}
 
-	if assertion.AnyHTTP(t.Assertions) {
+	assertions := assertion.WithMock(t.Assertions, t.Mock)
+
+	if assertion.AnyGlobalHTTP(assertions) {
 		httpmock.Activate()
 		defer httpmock.DeactivateAndReset()
 	}
 
-	err = t.setupAssertions()
+	err = t.setupAssertions(assertions)
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to setup assertions"))
+		return result.fail(PhaseSetup, "failed to setup assertions", err)
 	}
 
-	resp, err := t.call()
+	// the timeout limits the call only, the assertions run with the context of the test case
+	callCtx, cancel := withTimeout(ctx, t.Call.Timeout)
+	defer cancel()
+
+	resp, err := t.call(callCtx)
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to call GRPC endpoint"))
+		return result.fail(PhaseCall, "failed to call GRPC endpoint", err)
 	}
 
-	err = t.assert(resp)
-	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to assert GRPC response"))
+	result.response = &response{header: metadataHeader(resp.header), body: resp.body()}
+	for _, err := range t.assert(resp) {
+		result.fail(PhaseAssert, "failed to assert GRPC response", err)
 	}
 
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Assert()
-			if err != nil {
-				return errors.New(errString(err, t.Description, "failed to assert"))
-			}
+	for i, a := range assertions {
+		err := assertion.AssertContext(ctx, a)
+		if err != nil {
+			result.failAssertion(i+1, err)
+		}
+	}
+
+	return result
+}
+
+func (t *GRPCTestCase) setupAssertions(assertions []assertion.Assertion) error {
+	for _, assertion := range assertions {
+		err := assertion.Setup()
+		if err != nil {
+			return fmt.Errorf("failed to setup assertion: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func (t *GRPCTestCase) setupAssertions() error {
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Setup()
-			if err != nil {
-				return fmt.Errorf("failed to setup assertion: %w", err)
-			}
+// assert returns all differences between the response received and the expected one
+func (t *GRPCTestCase) assert(resp grpcResponse) []error {
+	errs := []error{}
+
+	if resp.stream {
+		errs = append(errs, t.assertMessages(resp.messages)...)
+	} else {
+		errs = append(errs, t.assertMessage(resp.message)...)
+	}
+
+	if t.Output.Schema != nil {
+		errs = append(errs, t.assertSchema(resp)...)
+	}
+
+	errs = append(errs, assertMetadata("header", t.Output.Header, resp.header)...)
+	errs = append(errs, assertMetadata("trailer", t.Output.Trailer, resp.trailer)...)
+
+	if t.Output.Status != nil {
+		return append(errs, t.assertStatus(resp.err)...)
+	}
+
+	err := t.assertErr(resp.err)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// assertSchema validates the messages received with the schema
+func (t *GRPCTestCase) assertSchema(resp grpcResponse) []error {
+	if !resp.stream {
+		if resp.err != nil {
+			return nil
+		}
+
+		content, err := marshalJSON(resp.message)
+		if err != nil {
+			return []error{fmt.Errorf("failed to marshal grpc response to json: %w", err)}
 		}
+
+		err = assertSchema("message", t.Output.Schema, content)
+		if err != nil {
+			return []error{err}
+		}
+		return nil
+	}
+
+	errs := []error{}
+	for i, message := range resp.messages {
+		content, err := marshalJSON(message)
+		if err != nil {
+			return []error{fmt.Errorf("failed to marshal grpc response to json: %w", err)}
+		}
+
+		err = assertSchema(fmt.Sprintf("message %d", i+1), t.Output.Schema, content)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// assertMetadata compares the keys of the expected metadata with the received one, other keys are ignored
+func assertMetadata(name string, expected, received metadata.MD) []error {
+	keys := []string{}
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	errs := []error{}
+	for _, key := range keys {
+		expectedValue := strings.Join(expected[key], ", ")
+		receivedValue := strings.Join(received.Get(key), ", ")
+		if expectedValue != receivedValue {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response %s '%s' should be '%s' it got '%s'", name, key, expectedValue, receivedValue),
+				expected: expectedValue,
+				actual:   receivedValue,
+			})
+		}
+	}
+	return errs
+}
+
+func (t *GRPCTestCase) assertMessage(message interface{}) []error {
+	if len(t.Output.Messages) > 0 {
+		return []error{errors.New("grpc function does not return a stream, the expected response should be set in the output message")}
+	}
+
+	respValueJSON, err := marshalJSON(message)
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal grpc response to json: %w", err)}
+	}
+
+	if t.Output.Message == nil && t.Output.Schema != nil {
+		// the message is only validated by the schema
+		return nil
+	}
+
+	expectedValueJSON, err := marshalJSON(t.Output.Message)
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal grpc expected response to json: %w", err)}
+	}
+
+	err = t.compare(t.Output.Message, message)
+	if err != nil {
+		return []error{&mismatchError{
+			err:      fmt.Errorf("body does not match: %v", err),
+			expected: string(expectedValueJSON),
+			actual:   string(respValueJSON),
+			diff:     t.diff(t.Output.Message, message),
+		}}
 	}
 
 	return nil
 }
 
-func (t *GRPCTestCase) assert(resp []reflect.Value) error {
-	respErr, _ := resp[1].Interface().(error)
+// assertMessages compares the messages received in a stream with the expected ones,
+// in order or, if the output is unordered, in any order
+func (t *GRPCTestCase) assertMessages(messages []interface{}) []error {
+	if t.Output.Message != nil {
+		return []error{errors.New("grpc function returns a stream, the expected responses should be set in the output messages")}
+	}
+
+	respValuesJSON, err := marshalMessages(messages)
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal grpc response to json: %w", err)}
+	}
+
+	respJSON, err := json.Marshal(respValuesJSON)
+	if err != nil {
+		return []error{fmt.Errorf("failed to marshal grpc response to json: %w", err)}
+	}
+
+	if len(t.Output.Messages) == 0 && t.Output.Schema != nil {
+		// the messages are only validated by the schema
+		return nil
+	}
 
-	respValueJSON, err := json.Marshal(resp[0].Interface())
+	expectedValuesJSON, err := marshalMessages(t.Output.Messages)
 	if err != nil {
-		return fmt.Errorf("failed to marshal grpc response to json: %w", err)
+		return []error{fmt.Errorf("failed to marshal grpc expected response to json: %w", err)}
 	}
 
-	expectedValueJSON, err := json.Marshal(t.Output.Message)
+	expectedJSON, err := json.Marshal(expectedValuesJSON)
 	if err != nil {
-		return fmt.Errorf("failed to marshal grpc expected response to json: %w", err)
+		return []error{fmt.Errorf("failed to marshal grpc expected response to json: %w", err)}
+	}
+
+	if len(respValuesJSON) != len(expectedValuesJSON) {
+		return []error{&mismatchError{
+			err:      fmt.Errorf("number of messages should be %d it got %d", len(expectedValuesJSON), len(respValuesJSON)),
+			expected: string(expectedJSON),
+			actual:   string(respJSON),
+		}}
 	}
 
-	je := utils.JsonError{}
-	jsonassert.New(&je).Assertf(string(respValueJSON), string(expectedValueJSON))
-	if je.Err != nil {
-		return fmt.Errorf("body does not match: %v", je.Err.Error())
+	errs := []error{}
+
+	if t.Output.Unordered {
+		received := make([]bool, len(messages))
+		for i, expected := range t.Output.Messages {
+			j := t.matchMessage(expected, messages, received)
+			if j < 0 {
+				errs = append(errs, &mismatchError{
+					err:      fmt.Errorf("message %d was not received: %s", i+1, expectedValuesJSON[i]),
+					expected: string(expectedJSON),
+					actual:   string(respJSON),
+				})
+				continue
+			}
+			received[j] = true
+		}
+		return errs
 	}
 
+	for i, expected := range t.Output.Messages {
+		err := t.compare(expected, messages[i])
+		if err != nil {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("message %d does not match: %v", i+1, err),
+				expected: string(expectedValuesJSON[i]),
+				actual:   string(respValuesJSON[i]),
+				diff:     t.diff(expected, messages[i]),
+			})
+		}
+	}
+
+	return errs
+}
+
+// matchMessage returns the position of the first message not received yet that matches the expected one, or -1
+func (t *GRPCTestCase) matchMessage(expected interface{}, messages []interface{}, received []bool) int {
+	for i, message := range messages {
+		if received[i] {
+			continue
+		}
+
+		if t.compare(expected, message) == nil {
+			return i
+		}
+	}
+	return -1
+}
+
+func marshalMessages(messages []interface{}) ([]json.RawMessage, error) {
+	values := []json.RawMessage{}
+	for _, message := range messages {
+		value, err := marshalJSON(message)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (t *GRPCTestCase) assertErr(respErr error) error {
 	if respErr == nil && t.Output.Err == nil {
 		return nil
 	}
 
-	if (respErr != nil && t.Output.Err == nil) || (respErr == nil && t.Output.Err != nil) {
-		return fmt.Errorf("error response should be %v it got %v", t.Output.Err, respErr)
+	if respErr == nil {
+		return &mismatchError{
+			err:      fmt.Errorf("error response should be %v it got none", t.Output.Err),
+			expected: fmt.Sprint(t.Output.Err),
+			actual:   fmt.Sprint(respErr),
+		}
 	}
 
-	if respErr != nil && t.Output.Err != nil {
-		status, ok := status.FromError(respErr)
-		if !ok {
-			return fmt.Errorf("failed to get error status %v", respErr)
+	if t.Output.Err == nil {
+		return &mismatchError{
+			err:      fmt.Errorf("error response should be none it got %w", respErr),
+			expected: fmt.Sprint(t.Output.Err),
+			actual:   fmt.Sprint(respErr),
 		}
+	}
 
-		if t.Output.Err.Code() != status.Code() {
-			return fmt.Errorf("error response status should be %v it got %v", t.Output.Err.Code(), status.Code())
+	status, ok := status.FromError(respErr)
+	if !ok {
+		return fmt.Errorf("failed to get error status %v", respErr)
+	}
+
+	if t.Output.Err.Code() != status.Code() {
+		return &mismatchError{
+			err:      fmt.Errorf("error response status should be %v it got %v: %w", t.Output.Err.Code(), status.Code(), respErr),
+			expected: t.Output.Err.Code().String(),
+			actual:   status.Code().String(),
 		}
+	}
 
-		if t.Output.Err.Message() != status.Message() {
-			return fmt.Errorf("error response message should be %v it got %v", t.Output.Err.Message(), status.Message())
+	if t.Output.Err.Message() != status.Message() {
+		return &mismatchError{
+			err:      fmt.Errorf("error response message should be %v it got %v", t.Output.Err.Message(), status.Message()),
+			expected: t.Output.Err.Message(),
+			actual:   status.Message(),
 		}
 	}
 
 	return nil
 }
 
-func (t *GRPCTestCase) call() ([]reflect.Value, error) {
+// outgoingContext adds the metadata and the authorization of the call to the outgoing metadata of the context
+func (t *GRPCTestCase) outgoingContext(ctx context.Context) (context.Context, error) {
+	md := t.Call.Metadata
+	if t.Call.Auth != nil {
+		authorization, err := t.Call.Auth.Authorization(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get authorization: %w", err)
+		}
+
+		md = md.Copy()
+		md.Set("authorization", authorization)
+	}
+
+	if len(md) == 0 {
+		return ctx, nil
+	}
+
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	return metadata.NewOutgoingContext(ctx, metadata.Join(outgoing, md)), nil
+}
+
+func (t *GRPCTestCase) call(ctx context.Context) (grpcResponse, error) {
+	if t.Call.ServiceClient == nil && t.Call.Target != "" {
+		return t.dynamicCall(ctx)
+	}
+
 	if t.Call.ServiceClient == nil {
-		return nil, fmt.Errorf("%s: failed because GRPC client is nil", t.Description)
+		return grpcResponse{}, fmt.Errorf("%s: failed because GRPC client is nil", t.Description)
 	}
 
-	args := []reflect.Value{reflect.ValueOf(context.Background()), reflect.ValueOf(t.Call.Message)}
 	function := reflect.ValueOf(t.Call.ServiceClient).MethodByName(t.Call.Function)
 	if !function.IsValid() {
-		return nil, errors.New(fmt.Sprintf("%s: failed because GRPC function is not valid", t.Description))
+		return grpcResponse{}, errors.New(fmt.Sprintf("%s: failed because GRPC function is not valid", t.Description))
+	}
+
+	if function.Type().NumIn() == 0 || function.Type().NumOut() != 2 {
+		return grpcResponse{}, fmt.Errorf("%s: failed because GRPC function is not valid", t.Description)
+	}
+
+	kind := streamKindOf(function.Type())
+
+	ctx, err := t.outgoingContext(ctx)
+	if err != nil {
+		return grpcResponse{}, fmt.Errorf("%s: %w", t.Description, err)
+	}
+
+	resp := grpcResponse{stream: kind == serverStreaming || kind == bidiStreaming}
+	options := callOptions(function.Type(), grpc.Header(&resp.header), grpc.Trailer(&resp.trailer))
+
+	if kind == unary || kind == serverStreaming {
+		if len(t.Call.Messages) > 0 || function.Type().NumIn() < 2 {
+			return grpcResponse{}, fmt.Errorf("%s: failed because GRPC function receives a single message, it should be set in the call message", t.Description)
+		}
+
+		message, err := argument(function.Type().In(1), t.Call.Message)
+		if err != nil {
+			return grpcResponse{}, fmt.Errorf("%s: %w", t.Description, err)
+		}
+
+		out := function.Call(append([]reflect.Value{reflect.ValueOf(ctx), message}, options...))
+		resp.err, _ = out[1].Interface().(error)
+
+		if kind == unary {
+			resp.message = out[0].Interface()
+			return resp, nil
+		}
+
+		if resp.err == nil {
+			resp.messages, resp.err = receive(out[0])
+		}
+		return resp, nil
+	}
+
+	out := function.Call(append([]reflect.Value{reflect.ValueOf(ctx)}, options...))
+	resp.err, _ = out[1].Interface().(error)
+	if resp.err != nil {
+		return resp, nil
+	}
+	stream := out[0]
+
+	err = send(stream, t.messages())
+	if err != nil {
+		return grpcResponse{}, fmt.Errorf("%s: %w", t.Description, err)
+	}
+
+	if kind == clientStreaming {
+		out = stream.MethodByName("CloseAndRecv").Call(nil)
+		resp.message = out[0].Interface()
+		resp.err, _ = out[1].Interface().(error)
+		return resp, nil
+	}
+
+	resp.err = stream.Interface().(grpc.ClientStream).CloseSend()
+	if resp.err == nil {
+		resp.messages, resp.err = receive(stream)
+	}
+	return resp, nil
+}
+
+// callOptions returns the options as values that can be passed to the function, if it receives call options
+func callOptions(function reflect.Type, options ...grpc.CallOption) []reflect.Value {
+	optionType := reflect.TypeOf((*grpc.CallOption)(nil)).Elem()
+	if !function.IsVariadic() || function.In(function.NumIn()-1).Elem() != optionType {
+		return nil
+	}
+
+	values := []reflect.Value{}
+	for _, option := range options {
+		values = append(values, reflect.ValueOf(option))
+	}
+	return values
+}
+
+// messages returns the messages sent to a client streaming or bidirectional streaming function
+func (t *GRPCTestCase) messages() []interface{} {
+	if len(t.Call.Messages) > 0 {
+		return t.Call.Messages
+	}
+
+	return []interface{}{t.Call.Message}
+}
+
+// streamKindOf finds the kind of a GRPC function by the methods of the value it returns
+func streamKindOf(function reflect.Type) streamKind {
+	out := function.Out(0)
+	_, send := out.MethodByName("Send")
+	_, recv := out.MethodByName("Recv")
+	_, closeAndRecv := out.MethodByName("CloseAndRecv")
+
+	switch {
+	case send && closeAndRecv:
+		return clientStreaming
+	case send && recv:
+		return bidiStreaming
+	case recv:
+		return serverStreaming
+	default:
+		return unary
+	}
+}
+
+// argument returns the message as a value that can be passed to a function expecting the type passed as param
+func argument(typ reflect.Type, message interface{}) (reflect.Value, error) {
+	if message == nil {
+		return reflect.Value{}, errors.New("grpc message is required")
+	}
+
+	value := reflect.ValueOf(message)
+	if !value.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("grpc message should be %v it got %v", typ, value.Type())
 	}
 
-	return function.Call(args), nil
+	return value, nil
+}
+
+// send sends all messages to the stream.
+// It stops if the stream is closed by the server, the error is received by the next call to the stream.
+func send(stream reflect.Value, messages []interface{}) error {
+	method := stream.MethodByName("Send")
+
+	for i, message := range messages {
+		value, err := argument(method.Type().In(0), message)
+		if err != nil {
+			return fmt.Errorf("failed to send message %d: %w", i+1, err)
+		}
+
+		out := method.Call([]reflect.Value{value})
+		err, _ = out[0].Interface().(error)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to send message %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// receive receives messages from the stream until it ends.
+// The error returned is the status of the stream if it didn't end successfully.
+func receive(stream reflect.Value) ([]interface{}, error) {
+	method := stream.MethodByName("Recv")
+	messages := []interface{}{}
+
+	for {
+		out := method.Call(nil)
+		err, _ := out[1].Interface().(error)
+		if errors.Is(err, io.EOF) {
+			return messages, nil
+		}
+		if err != nil {
+			return messages, err
+		}
+
+		messages = append(messages, out[0].Interface())
+	}
+}
+
+// body returns the messages received as a JSON, or nil if they can't be marshaled.
+// A stream returns a JSON list with its messages.
+func (r grpcResponse) body() []byte {
+	if !r.stream {
+		content, err := marshalJSON(r.message)
+		if err != nil {
+			return nil
+		}
+		return content
+	}
+
+	values, err := marshalMessages(r.messages)
+	if err != nil {
+		return nil
+	}
+
+	content, err := json.Marshal(values)
+	if err != nil {
+		return nil
+	}
+	return content
+}
+
+// metadataHeader returns the metadata as an HTTP header, so it can be captured like the header of an HTTP response
+func metadataHeader(md metadata.MD) http.Header {
+	h := http.Header{}
+	for key, values := range md {
+		for _, value := range values {
+			h.Add(key, value)
+		}
+	}
+	return h
+}
+
+func (t *GRPCTestCase) interpolate(vars map[string]string) (Tester, error) {
+	i := interpolation{vars: vars}
+
+	interpolated := *t
+	interpolated.Call.Target = i.string(t.Call.Target)
+	interpolated.Call.Message = i.message(t.Call.Message)
+	interpolated.Call.Messages = i.messages(t.Call.Messages)
+	interpolated.Output.Message = i.message(t.Output.Message)
+	interpolated.Output.Messages = i.messages(t.Output.Messages)
+	interpolated.Call.Metadata = i.metadata(t.Call.Metadata)
+	interpolated.Call.Auth = i.auth(t.Call.Auth)
+	interpolated.Output.Header = i.metadata(t.Output.Header)
+	interpolated.Output.Trailer = i.metadata(t.Output.Trailer)
+	if t.Output.Status != nil {
+		st := *t.Output.Status
+		st.Message = i.string(st.Message)
+		st.MessageContains = i.string(st.MessageContains)
+		st.MessageRegex = i.string(st.MessageRegex)
+		st.Details = i.messages(st.Details)
+		interpolated.Output.Status = &st
+	}
+	interpolated.Assertions = i.assertions(t.Assertions)
+
+	return &interpolated, i.err
 }
 
 func (t *GRPCTestCase) validate() error {
-	if t.Call.ServiceClient == nil {
-		return errors.New("grpc client is required")
+	if t.Call.ServiceClient == nil && t.Call.Target == "" {
+		return errors.New("grpc client or target is required")
 	}
 
-	if t.Call.Function == "" {
+	if t.Call.ServiceClient != nil && t.Call.Function == "" {
 		return errors.New("grpc function is required")
 	}
 
-	if t.Call.Message == nil {
+	if t.Call.ServiceClient == nil && t.Call.Method == "" {
+		return errors.New("grpc method is required")
+	}
+
+	if t.Call.Message == nil && len(t.Call.Messages) == 0 {
 		return errors.New("grpc message is required")
 	}
 
+	for i, message := range t.Call.Messages {
+		if message == nil {
+			return fmt.Errorf("grpc message %d is required", i+1)
+		}
+	}
+
+	if t.Output.Err != nil && t.Output.Status != nil {
+		return errors.New("grpc output can't have both error and status")
+	}
+
+	if t.Output.Schema != nil {
+		_, err := compileSchema(t.Output.Schema)
+		if err != nil {
+			return fmt.Errorf("grpc output schema is invalid: %w", err)
+		}
+	}
+
+	if t.Output.Status != nil && t.Output.Status.MessageRegex != "" {
+		_, err := regexp.Compile(t.Output.Status.MessageRegex)
+		if err != nil {
+			return fmt.Errorf("grpc status message regex is invalid: %w", err)
+		}
+	}
+
 	return nil
 }
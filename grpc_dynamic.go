@@ -0,0 +1,274 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// descriptorResolver finds descriptors by their fully-qualified name
+type descriptorResolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// dynamicCall calls the method of the target, sending and receiving the messages as JSON
+func (t *GRPCTestCase) dynamicCall(ctx context.Context) (grpcResponse, error) {
+	conn, err := grpc.DialContext(ctx, t.Call.Target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return grpcResponse{}, fmt.Errorf("%s: failed to connect to GRPC server: %w", t.Description, err)
+	}
+	defer conn.Close()
+
+	// the metadata and the authorization are also sent to the server reflection
+	ctx, err = t.outgoingContext(ctx)
+	if err != nil {
+		return grpcResponse{}, fmt.Errorf("%s: %w", t.Description, err)
+	}
+
+	method, err := t.method(ctx, conn)
+	if err != nil {
+		return grpcResponse{}, fmt.Errorf("%s: %w", t.Description, err)
+	}
+
+	if !method.IsStreamingClient() && len(t.Call.Messages) > 0 {
+		return grpcResponse{}, fmt.Errorf("%s: failed because GRPC function receives a single message, it should be set in the call message", t.Description)
+	}
+
+	requests := []proto.Message{}
+	for i, message := range t.messages() {
+		request := dynamicpb.NewMessage(method.Input())
+		err := unmarshalMessage(message, request)
+		if err != nil {
+			return grpcResponse{}, fmt.Errorf("%s: failed to create message %d: %w", t.Description, i+1, err)
+		}
+		requests = append(requests, request)
+	}
+
+	resp := grpcResponse{stream: method.IsStreamingServer()}
+	desc := &grpc.StreamDesc{ServerStreams: method.IsStreamingServer(), ClientStreams: method.IsStreamingClient()}
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+
+	stream, err := conn.NewStream(ctx, desc, fullMethod, grpc.Header(&resp.header), grpc.Trailer(&resp.trailer))
+	if err != nil {
+		resp.err = err
+		return resp, nil
+	}
+
+	for i, request := range requests {
+		err := stream.SendMsg(request)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return grpcResponse{}, fmt.Errorf("%s: failed to send message %d: %w", t.Description, i+1, err)
+		}
+	}
+
+	resp.err = stream.CloseSend()
+	if resp.err != nil {
+		return resp, nil
+	}
+
+	if !resp.stream {
+		resp.message, resp.err = receiveMessage(stream, method.Output())
+		return resp, nil
+	}
+
+	resp.messages = []interface{}{}
+	for {
+		message, err := receiveMessage(stream, method.Output())
+		if errors.Is(err, io.EOF) {
+			return resp, nil
+		}
+		if err != nil {
+			resp.err = err
+			return resp, nil
+		}
+		resp.messages = append(resp.messages, message)
+	}
+}
+
+// method finds the descriptor of the method called, using the descriptor set, the proto files or server reflection
+func (t *GRPCTestCase) method(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName, ok := strings.Cut(strings.TrimPrefix(t.Call.Method, "/"), "/")
+	if !ok {
+		return nil, fmt.Errorf("grpc method '%s' should be service/method", t.Call.Method)
+	}
+
+	var resolver descriptorResolver
+	var err error
+	switch {
+	case t.Call.DescriptorSet != "":
+		resolver, err = descriptorSetResolver(t.Call.DescriptorSet)
+	case len(t.Call.ProtoFiles) > 0:
+		resolver, err = protoFilesResolver(ctx, t.Call.ImportPaths, t.Call.ProtoFiles)
+	default:
+		resolver, err = reflectionResolver(ctx, conn, serviceName)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := resolver.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find grpc service '%s': %w", serviceName, err)
+	}
+
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a grpc service", serviceName)
+	}
+
+	method := service.Methods().ByName(protoreflect.Name(methodName))
+	if method == nil {
+		return nil, fmt.Errorf("grpc service '%s' does not have the method '%s'", serviceName, methodName)
+	}
+
+	return method, nil
+}
+
+func descriptorSetResolver(path string) (descriptorResolver, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	err = proto.Unmarshal(content, set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set: %w", err)
+	}
+
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load descriptor set: %w", err)
+	}
+
+	return files, nil
+}
+
+func protoFilesResolver(ctx context.Context, importPaths, protoFiles []string) (descriptorResolver, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+
+	files, err := compiler.Compile(ctx, protoFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+
+	return files.AsResolver(), nil
+}
+
+// reflectionResolver asks the server for the file that has the symbol and all files it imports
+func reflectionResolver(ctx context.Context, conn *grpc.ClientConn, symbol string) (descriptorResolver, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call grpc server reflection: %w", err)
+	}
+	defer stream.CloseSend()
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+
+	request := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: symbol},
+	}
+	for request != nil {
+		err := reflectionFiles(stream, request, files)
+		if err != nil {
+			return nil, err
+		}
+
+		previous := request
+		request = nil
+		for _, file := range files {
+			for _, dependency := range file.GetDependency() {
+				if _, ok := files[dependency]; ok {
+					continue
+				}
+
+				if previous.GetFileByFilename() == dependency {
+					return nil, fmt.Errorf("grpc server reflection did not return the file '%s'", dependency)
+				}
+				request = &rpb.ServerReflectionRequest{
+					MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+				}
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+
+	resolver, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load files returned by grpc server reflection: %w", err)
+	}
+
+	return resolver, nil
+}
+
+// reflectionFiles sends the request to the server reflection and adds the files received
+func reflectionFiles(stream rpb.ServerReflection_ServerReflectionInfoClient, request *rpb.ServerReflectionRequest, files map[string]*descriptorpb.FileDescriptorProto) error {
+	err := stream.Send(request)
+	if err != nil {
+		return fmt.Errorf("failed to call grpc server reflection: %w", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to call grpc server reflection: %w", err)
+	}
+
+	if errResp := resp.GetErrorResponse(); errResp != nil {
+		return fmt.Errorf("grpc server reflection failed: %s", errResp.GetErrorMessage())
+	}
+
+	for _, content := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+		file := &descriptorpb.FileDescriptorProto{}
+		err := proto.Unmarshal(content, file)
+		if err != nil {
+			return fmt.Errorf("failed to parse file returned by grpc server reflection: %w", err)
+		}
+		files[file.GetName()] = file
+	}
+
+	return nil
+}
+
+// receiveMessage receives a message of the stream
+func receiveMessage(stream grpc.ClientStream, descriptor protoreflect.MessageDescriptor) (interface{}, error) {
+	message := dynamicpb.NewMessage(descriptor)
+	err := stream.RecvMsg(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// unmarshalMessage fills the proto message with a JSON string or a value marshaled to JSON
+func unmarshalMessage(value interface{}, message proto.Message) error {
+	content, err := marshalJSON(value)
+	if err != nil {
+		return err
+	}
+
+	return protojson.Unmarshal(content, message)
+}
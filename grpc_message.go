@@ -0,0 +1,280 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/lucasvmiguel/integration/matcher"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// compare returns why the message received doesn't match the expected one, or nil if it matches
+func (t *GRPCTestCase) compare(expected, received interface{}) error {
+	if t.Output.Exact {
+		return t.compareExact(expected, received)
+	}
+
+	expected, err := expectedMessage(expected, received)
+	if err != nil {
+		return err
+	}
+
+	expectedJSON, err := t.comparableJSON(expected)
+	if err != nil {
+		return fmt.Errorf("failed to marshal grpc expected response to json: %w", err)
+	}
+
+	receivedJSON, err := t.comparableJSON(received)
+	if err != nil {
+		return fmt.Errorf("failed to marshal grpc response to json: %w", err)
+	}
+
+	return matcher.JSON(string(receivedJSON), string(expectedJSON))
+}
+
+// diff returns a unified diff of the received message from the expected one, without the ignored fields
+func (t *GRPCTestCase) diff(expected, received interface{}) string {
+	expected, err := expectedMessage(expected, received)
+	if err != nil {
+		return ""
+	}
+
+	expectedJSON, err := t.comparableJSON(expected)
+	if err != nil {
+		return ""
+	}
+
+	receivedJSON, err := t.comparableJSON(received)
+	if err != nil {
+		return ""
+	}
+
+	return bodyDiff(string(expectedJSON), string(receivedJSON))
+}
+
+// expectedMessage returns an expected message written as JSON as a message of the type received,
+// so it's marshaled as the received one (eg: user_id as userId and int64 as a string).
+// JSON with placeholders (eg: <<PRESENCE>>) can't be unmarshaled, so it's returned as it is.
+func expectedMessage(expected, received interface{}) (interface{}, error) {
+	content, ok := expected.(string)
+	if !ok || strings.Contains(content, "<<") || isNil(received) {
+		return expected, nil
+	}
+
+	receivedMessage, ok := received.(proto.Message)
+	if !ok {
+		return expected, nil
+	}
+
+	message := receivedMessage.ProtoReflect().New().Interface()
+	err := protojson.Unmarshal([]byte(content), message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal grpc expected response to %s: %w", message.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	return message, nil
+}
+
+// comparableJSON returns the message as JSON without the ignored fields
+func (t *GRPCTestCase) comparableJSON(message interface{}) ([]byte, error) {
+	content, err := marshalJSON(message)
+	if err != nil || len(t.Output.IgnoreFields) == 0 {
+		return content, err
+	}
+
+	var document interface{}
+	err = json.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range t.Output.IgnoreFields {
+		deleteField(document, strings.Split(path, "."))
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(document)
+	return buffer.Bytes(), err
+}
+
+// compareExact compares the messages with proto.Equal, after clearing the ignored fields
+func (t *GRPCTestCase) compareExact(expected, received interface{}) error {
+	if isNil(expected) || isNil(received) {
+		if isNil(expected) && isNil(received) {
+			return nil
+		}
+		return errors.New("a message was expected and the other was received empty, or the other way around")
+	}
+
+	receivedMessage, ok := received.(proto.Message)
+	if !ok {
+		return errors.New("exact comparison requires a proto message")
+	}
+
+	expectedMessage, err := convertMessage(expected, receivedMessage)
+	if err != nil {
+		return fmt.Errorf("failed to convert expected message to %s: %w", receivedMessage.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	receivedMessage = proto.Clone(receivedMessage)
+	expectedMessage = proto.Clone(expectedMessage)
+
+	for _, path := range t.Output.IgnoreFields {
+		names := strings.Split(path, ".")
+
+		err := clearField(receivedMessage.ProtoReflect(), names)
+		if err != nil {
+			return err
+		}
+
+		err = clearField(expectedMessage.ProtoReflect(), names)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !proto.Equal(expectedMessage, receivedMessage) {
+		return errors.New("messages are not equal")
+	}
+
+	return nil
+}
+
+// convertMessage returns the message with the type of the one passed as param, converting it from its JSON if needed
+func convertMessage(value interface{}, to proto.Message) (proto.Message, error) {
+	message, ok := value.(proto.Message)
+	if ok && message.ProtoReflect().Descriptor() == to.ProtoReflect().Descriptor() {
+		return message, nil
+	}
+
+	converted := to.ProtoReflect().New().Interface()
+	err := unmarshalMessage(value, converted)
+	if err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+// clearField clears the field of the path, where names are the field names of the path.
+// Fields of messages in lists are cleared in all items.
+func clearField(m protoreflect.Message, names []string) error {
+	fields := m.Descriptor().Fields()
+	fd := fields.ByName(protoreflect.Name(names[0]))
+	if fd == nil {
+		fd = fields.ByJSONName(names[0])
+	}
+	if fd == nil {
+		return fmt.Errorf("message %s does not have the field '%s'", m.Descriptor().FullName(), names[0])
+	}
+
+	if len(names) == 1 {
+		m.Clear(fd)
+		return nil
+	}
+
+	if fd.Message() == nil || fd.IsMap() {
+		return fmt.Errorf("field '%s' of message %s is not a message", names[0], m.Descriptor().FullName())
+	}
+
+	if !m.Has(fd) {
+		return nil
+	}
+
+	if !fd.IsList() {
+		return clearField(m.Mutable(fd).Message(), names[1:])
+	}
+
+	list := m.Mutable(fd).List()
+	for i := 0; i < list.Len(); i++ {
+		err := clearField(list.Get(i).Message(), names[1:])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteField deletes the field of the path from a JSON document, where names are the field names of the path.
+// Each name is looked up as written and in the lowerCamelCase used by protojson.
+func deleteField(document interface{}, names []string) {
+	switch document := document.(type) {
+	case []interface{}:
+		for _, item := range document {
+			deleteField(item, names)
+		}
+	case map[string]interface{}:
+		for _, key := range []string{names[0], jsonName(names[0])} {
+			if len(names) == 1 {
+				delete(document, key)
+				continue
+			}
+
+			child, ok := document[key]
+			if ok {
+				deleteField(child, names[1:])
+			}
+		}
+	}
+}
+
+// jsonName returns the lowerCamelCase name used by protojson for a field name (eg: created_at is createdAt)
+func jsonName(name string) string {
+	builder := strings.Builder{}
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+
+		if upper && 'a' <= r && r <= 'z' {
+			r -= 'a' - 'A'
+		}
+		upper = false
+		builder.WriteRune(r)
+	}
+	return builder.String()
+}
+
+// marshalJSON returns a string as it is, as it's already JSON.
+// Proto messages are marshaled with protojson and other values with encoding/json.
+func marshalJSON(value interface{}) ([]byte, error) {
+	if isNil(value) {
+		return []byte("null"), nil
+	}
+
+	switch value := value.(type) {
+	case string:
+		return []byte(value), nil
+	case proto.Message:
+		content, err := protojson.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		// protojson doesn't have a stable output, it's compacted so it can be compared and shown in the results
+		buffer := bytes.Buffer{}
+		err = json.Compact(&buffer, content)
+		return buffer.Bytes(), err
+	}
+
+	return json.Marshal(value)
+}
+
+func isNil(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	return v.Kind() == reflect.Pointer && v.IsNil()
+}
@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestCompare_WellKnownTypes(t *testing.T) {
+	testCase := &GRPCTestCase{}
+
+	date := time.Date(2023, time.January, 2, 3, 4, 5, 0, time.UTC)
+	err := testCase.compare(`"2023-01-02T03:04:05Z"`, timestamppb.New(date))
+	if err != nil {
+		t.Fatalf("timestamps should be compared as RFC 3339: %v", err)
+	}
+
+	err = testCase.compare(wrapperspb.String("foo"), wrapperspb.String("foo"))
+	if err != nil {
+		t.Fatalf("wrappers should be compared as their value: %v", err)
+	}
+
+	value, err := structpb.NewStruct(map[string]interface{}{"title": "foo", "id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = testCase.compare(`{"title": "<<PRESENCE>>", "id": 1}`, value)
+	if err != nil {
+		t.Fatalf("<<PRESENCE>> should be supported: %v", err)
+	}
+}
+
+func TestCompare_JSONMessage(t *testing.T) {
+	testCase := &GRPCTestCase{}
+	received := &descriptorpb.UninterpretedOption{
+		IdentifierValue:  proto.String("foo"),
+		NegativeIntValue: proto.Int64(-9007199254740993),
+	}
+
+	err := testCase.compare(`{"identifier_value": "foo", "negative_int_value": -9007199254740993}`, received)
+	if err != nil {
+		t.Fatalf("proto names and int64 numbers should be supported: %v", err)
+	}
+
+	err = testCase.compare(`{"identifierValue": "foo", "negativeIntValue": "-9007199254740993"}`, received)
+	if err != nil {
+		t.Fatalf("lowerCamelCase names and int64 strings should be supported: %v", err)
+	}
+
+	err = testCase.compare(`{"identifier_value": "foo", "negative_int_value": -9007199254740992}`, received)
+	if err == nil {
+		t.Fatal("int64 numbers should be compared without losing precision")
+	}
+
+	err = testCase.compare(`{"identifierValue": "<<PRESENCE>>", "negativeIntValue": "-9007199254740993"}`, received)
+	if err != nil {
+		t.Fatalf("placeholders should be supported with the names and values of protojson: %v", err)
+	}
+
+	err = testCase.compare(`{"unknown": 1}`, received)
+	if err == nil || !strings.Contains(err.Error(), "failed to unmarshal grpc expected response to google.protobuf.UninterpretedOption") {
+		t.Fatalf("it should fail due to the unknown field, it got %v", err)
+	}
+}
+
+func TestCompare_IgnoreFields(t *testing.T) {
+	testCase := &GRPCTestCase{Output: expect.Output{IgnoreFields: []string{"comment"}}}
+
+	err := testCase.compare(&chat.Message{Id: 1, Comment: "foo"}, &chat.Message{Id: 1, Comment: "bar"})
+	if err != nil {
+		t.Fatalf("ignored fields should not be compared: %v", err)
+	}
+
+	err = testCase.compare(`{"id": 1}`, &chat.Message{Id: 2, Comment: "bar"})
+	if err == nil {
+		t.Fatal("fields that are not ignored should be compared")
+	}
+}
+
+func TestCompare_Exact(t *testing.T) {
+	testCase := &GRPCTestCase{Output: expect.Output{Exact: true}}
+
+	err := testCase.compare(&chat.Message{Id: 1, Body: "foo"}, &chat.Message{Id: 1, Body: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = testCase.compare(`{"id": 1, "body": "foo"}`, &chat.Message{Id: 1, Body: "foo"})
+	if err != nil {
+		t.Fatalf("a JSON message should be converted to the type received: %v", err)
+	}
+
+	err = testCase.compare(&chat.Message{Id: 1, Body: "<<PRESENCE>>"}, &chat.Message{Id: 1, Body: "foo"})
+	if err == nil {
+		t.Fatal("<<PRESENCE>> should not be supported by exact comparisons")
+	}
+
+	testCase.Output.IgnoreFields = []string{"body"}
+	err = testCase.compare(&chat.Message{Id: 1}, &chat.Message{Id: 1, Body: "foo"})
+	if err != nil {
+		t.Fatalf("ignored fields should not be compared: %v", err)
+	}
+
+	testCase.Output.IgnoreFields = []string{"unknown"}
+	err = testCase.compare(&chat.Message{Id: 1}, &chat.Message{Id: 1})
+	if err == nil {
+		t.Fatal("fields that don't exist should not be ignored")
+	}
+}
+
+func TestJSONName(t *testing.T) {
+	if name := jsonName("created_at"); name != "createdAt" {
+		t.Fatalf("it should be createdAt, it got %s", name)
+	}
+
+	if name := jsonName("id"); name != "id" {
+		t.Fatalf("it should be id, it got %s", name)
+	}
+}
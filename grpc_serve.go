@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// bufconnSize is the size of the in memory buffer of the connection
+const bufconnSize = 1024 * 1024
+
+// ServeGRPC serves the GRPC server in memory, without listening on a port, and returns a connection to it.
+// The connection can be used to create the ServiceClient of a GRPC test case (eg: chat.NewChatServiceClient(conn)).
+// The connection is closed and the server is stopped when the test finishes.
+func ServeGRPC(t testing.TB, server *grpc.Server) *grpc.ClientConn {
+	t.Helper()
+
+	listener := bufconn.Listen(bufconnSize)
+	go server.Serve(listener)
+
+	conn, err := grpc.DialContext(context.Background(), "passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		server.Stop()
+		t.Fatalf("failed to connect to GRPC server: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Stop()
+	})
+
+	return conn
+}
+
+// ServeGRPCServices creates a GRPC server with the options, registers the services with register
+// and serves it like ServeGRPC.
+// eg: ServeGRPCServices(t, func(s *grpc.Server) { chat.RegisterChatServiceServer(s, &Server{}) })
+func ServeGRPCServices(t testing.TB, register func(server *grpc.Server), opts ...grpc.ServerOption) *grpc.ClientConn {
+	t.Helper()
+
+	server := grpc.NewServer(opts...)
+	register(server)
+
+	return ServeGRPC(t, server)
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestServeGRPC(t *testing.T) {
+	server := grpc.NewServer()
+	chat.RegisterChatServiceServer(server, &Server{})
+
+	c := chat.NewChatServiceClient(ServeGRPC(t, server))
+
+	RunParallel(t,
+		&GRPCTestCase{
+			Description: "error",
+			Call: call.Call{
+				ServiceClient: c,
+				Function:      "SayHello",
+				Message:       &chat.Message{Body: errMessage},
+			},
+			Output: expect.Output{
+				Err: status.New(codes.Unavailable, errMessage),
+			},
+		},
+		&GRPCTestCase{
+			Description: "invalid",
+			Call: call.Call{
+				ServiceClient: c,
+				Function:      "SayHello",
+				Message:       &chat.Message{Body: invalidMessage},
+			},
+			Output: expect.Output{
+				Status: &expect.Status{Code: codes.InvalidArgument},
+			},
+		},
+	)
+}
+
+func TestServeGRPCServices(t *testing.T) {
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		s.RegisterService(&streamServiceDesc, &StreamServer{})
+	})
+
+	Run(t, &GRPCTestCase{
+		Description: "echo",
+		Call: call.Call{
+			ServiceClient: &streamClient{conn},
+			Function:      "Echo",
+			Messages:      []interface{}{&chat.Message{Id: 1, Body: "first"}},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{&chat.Message{Id: 1, Body: "first"}},
+		},
+	})
+}
@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lucasvmiguel/integration/matcher"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// assertStatus returns all differences between the status received and the expected one
+func (t *GRPCTestCase) assertStatus(respErr error) []error {
+	expected := t.Output.Status
+
+	if respErr == nil {
+		return []error{&mismatchError{
+			err:      fmt.Errorf("error response status should be %v it got no error", expected.Code),
+			expected: expected.Code.String(),
+			actual:   fmt.Sprint(respErr),
+		}}
+	}
+
+	st, ok := status.FromError(respErr)
+	if !ok {
+		return []error{fmt.Errorf("failed to get error status %v", respErr)}
+	}
+
+	errs := []error{}
+
+	if st.Code() != expected.Code {
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("error response status should be %v it got %v: %w", expected.Code, st.Code(), respErr),
+			expected: expected.Code.String(),
+			actual:   st.Code().String(),
+		})
+	}
+
+	if expected.Message != "" && st.Message() != expected.Message {
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("error response message should be %v it got %v", expected.Message, st.Message()),
+			expected: expected.Message,
+			actual:   st.Message(),
+		})
+	}
+
+	if expected.MessageContains != "" && !strings.Contains(st.Message(), expected.MessageContains) {
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("error response message should contain '%s' it got '%s'", expected.MessageContains, st.Message()),
+			expected: expected.MessageContains,
+			actual:   st.Message(),
+		})
+	}
+
+	if expected.MessageRegex != "" {
+		regex, err := regexp.Compile(expected.MessageRegex)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to compile error response message regex: %w", err))
+		} else if !regex.MatchString(st.Message()) {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("error response message should match '%s' it got '%s'", expected.MessageRegex, st.Message()),
+				expected: expected.MessageRegex,
+				actual:   st.Message(),
+			})
+		}
+	}
+
+	details := st.Proto().GetDetails()
+	for i, detail := range expected.Details {
+		err := matchDetail(detail, details)
+		if err != nil {
+			expectedJSON, _ := marshalJSON(detail)
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("error response detail %d was not received: %v", i+1, err),
+				expected: string(expectedJSON),
+				actual:   detailsJSON(details),
+			})
+		}
+	}
+
+	return errs
+}
+
+// matchDetail returns nil if any of the details received has the type and the content of the expected one
+func matchDetail(expected interface{}, details []*anypb.Any) error {
+	typeName, expectedJSON, err := detailJSON(expected)
+	if err != nil {
+		return err
+	}
+
+	for _, detail := range details {
+		if string(detail.MessageName()) != typeName {
+			continue
+		}
+
+		message, err := unpackDetail(detail, expected)
+		if err != nil {
+			continue
+		}
+
+		receivedJSON, err := marshalJSON(message)
+		if err != nil {
+			continue
+		}
+
+		err = matcher.JSON(string(receivedJSON), string(expectedJSON))
+		if err == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no detail of type %s matches", typeName)
+}
+
+// detailJSON returns the type name and the JSON content of an expected detail
+func detailJSON(detail interface{}) (string, []byte, error) {
+	if message, ok := detail.(proto.Message); ok {
+		content, err := marshalJSON(message)
+		return string(message.ProtoReflect().Descriptor().FullName()), content, err
+	}
+
+	content, err := marshalJSON(detail)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal detail to json: %w", err)
+	}
+
+	var fields map[string]interface{}
+	err = json.Unmarshal(content, &fields)
+	if err != nil {
+		return "", nil, fmt.Errorf("detail is not a JSON object: %w", err)
+	}
+
+	typeURL, _ := fields["@type"].(string)
+	if typeURL == "" {
+		return "", nil, errors.New("detail should be a proto message or have an @type")
+	}
+	delete(fields, "@type")
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(fields)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal detail to json: %w", err)
+	}
+
+	return typeURL[strings.LastIndex(typeURL, "/")+1:], buffer.Bytes(), nil
+}
+
+// unpackDetail returns the message of a detail, using the type of the expected detail if it's not registered
+func unpackDetail(detail *anypb.Any, expected interface{}) (proto.Message, error) {
+	message, err := detail.UnmarshalNew()
+	if err == nil {
+		return message, nil
+	}
+
+	expectedMessage, ok := expected.(proto.Message)
+	if !ok {
+		return nil, err
+	}
+
+	message = expectedMessage.ProtoReflect().New().Interface()
+	err = detail.UnmarshalTo(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// detailsJSON returns the details received as a JSON list, with their types in the "@type" field
+func detailsJSON(details []*anypb.Any) string {
+	values := []json.RawMessage{}
+	for _, detail := range details {
+		content, err := protojson.Marshal(detail)
+		if err != nil {
+			content, _ = json.Marshal(map[string]string{"@type": detail.GetTypeUrl()})
+		}
+		values = append(values, content)
+	}
+
+	content, _ := json.Marshal(values)
+	return string(content)
+}
@@ -0,0 +1,489 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// streamServiceServer is a service with streaming functions, written like the code generated by protoc-gen-go-grpc
+type streamServiceServer interface {
+	List(*chat.Message, streamServiceListServer) error
+	Collect(streamServiceCollectServer) error
+	Echo(streamServiceEchoServer) error
+}
+
+type streamServiceListServer interface {
+	Send(*chat.Message) error
+	grpc.ServerStream
+}
+
+type streamServiceCollectServer interface {
+	SendAndClose(*chat.Message) error
+	Recv() (*chat.Message, error)
+	grpc.ServerStream
+}
+
+type streamServiceEchoServer interface {
+	Send(*chat.Message) error
+	Recv() (*chat.Message, error)
+	grpc.ServerStream
+}
+
+type streamServiceStream struct {
+	grpc.ServerStream
+}
+
+func (x *streamServiceStream) Send(m *chat.Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *streamServiceStream) SendAndClose(m *chat.Message) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *streamServiceStream) Recv() (*chat.Message, error) {
+	m := new(chat.Message)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var streamServiceDesc = grpc.ServiceDesc{
+	ServiceName: "chat.StreamService",
+	HandlerType: (*streamServiceServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName: "List",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				m := new(chat.Message)
+				if err := stream.RecvMsg(m); err != nil {
+					return err
+				}
+				return srv.(streamServiceServer).List(m, &streamServiceStream{stream})
+			},
+			ServerStreams: true,
+		},
+		{
+			StreamName: "Collect",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				return srv.(streamServiceServer).Collect(&streamServiceStream{stream})
+			},
+			ClientStreams: true,
+		},
+		{
+			StreamName: "Echo",
+			Handler: func(srv interface{}, stream grpc.ServerStream) error {
+				return srv.(streamServiceServer).Echo(&streamServiceStream{stream})
+			},
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+}
+
+type streamServiceClient interface {
+	List(ctx context.Context, in *chat.Message, opts ...grpc.CallOption) (streamServiceListClient, error)
+	Collect(ctx context.Context, opts ...grpc.CallOption) (streamServiceCollectClient, error)
+	Echo(ctx context.Context, opts ...grpc.CallOption) (streamServiceEchoClient, error)
+}
+
+type streamServiceListClient interface {
+	Recv() (*chat.Message, error)
+	grpc.ClientStream
+}
+
+type streamServiceCollectClient interface {
+	Send(*chat.Message) error
+	CloseAndRecv() (*chat.Message, error)
+	grpc.ClientStream
+}
+
+type streamServiceEchoClient interface {
+	Send(*chat.Message) error
+	Recv() (*chat.Message, error)
+	grpc.ClientStream
+}
+
+type streamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func (c *streamClient) List(ctx context.Context, in *chat.Message, opts ...grpc.CallOption) (streamServiceListClient, error) {
+	stream, err := c.cc.NewStream(ctx, &streamServiceDesc.Streams[0], "/chat.StreamService/List", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamClientStream{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+func (c *streamClient) Collect(ctx context.Context, opts ...grpc.CallOption) (streamServiceCollectClient, error) {
+	stream, err := c.cc.NewStream(ctx, &streamServiceDesc.Streams[1], "/chat.StreamService/Collect", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &streamClientStream{stream}, nil
+}
+
+func (c *streamClient) Echo(ctx context.Context, opts ...grpc.CallOption) (streamServiceEchoClient, error) {
+	stream, err := c.cc.NewStream(ctx, &streamServiceDesc.Streams[2], "/chat.StreamService/Echo", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &streamClientStream{stream}, nil
+}
+
+type streamClientStream struct {
+	grpc.ClientStream
+}
+
+func (x *streamClientStream) Send(m *chat.Message) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *streamClientStream) Recv() (*chat.Message, error) {
+	m := new(chat.Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (x *streamClientStream) CloseAndRecv() (*chat.Message, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(chat.Message)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+type StreamServer struct{}
+
+// List sends Id messages with the body received, failing after the first message if the body is errMessage
+func (s *StreamServer) List(in *chat.Message, stream streamServiceListServer) error {
+	for i := 1; i <= int(in.Id); i++ {
+		err := stream.Send(&chat.Message{Id: int32(i), Body: fmt.Sprintf("%s %d", in.Body, i)})
+		if err != nil {
+			return err
+		}
+
+		if in.Body == errMessage {
+			return status.Error(codes.Unavailable, errMessage)
+		}
+	}
+	return nil
+}
+
+// Collect receives messages until the client half-closes the stream and responds with all bodies received
+func (s *StreamServer) Collect(stream streamServiceCollectServer) error {
+	bodies := []string{}
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&chat.Message{Id: int32(len(bodies)), Body: strings.Join(bodies, ", ")})
+		}
+		if err != nil {
+			return err
+		}
+		bodies = append(bodies, in.Body)
+	}
+}
+
+// Echo sends back every message received, failing if the body is errMessage
+func (s *StreamServer) Echo(stream streamServiceEchoServer) error {
+	for {
+		in, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if in.Body == errMessage {
+			return status.Error(codes.Unavailable, errMessage)
+		}
+
+		err = stream.Send(in)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func TestGRPC_ServerStreaming(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_ServerStreaming",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Message:       &chat.Message{Id: 3, Body: "hello"},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "hello 1"},
+				&chat.Message{Id: 2, Body: "hello 2"},
+				&chat.Message{Id: 3, Body: "<<PRESENCE>>"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_ServerStreamingUnordered(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_ServerStreamingUnordered",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Message:       &chat.Message{Id: 2, Body: "hello"},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 2, Body: "hello 2"},
+				&chat.Message{Id: 1, Body: "hello 1"},
+			},
+			Unordered: true,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_ServerStreamingWrongMessages(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_ServerStreamingWrongMessages",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Message:       &chat.Message{Id: 2, Body: "hello"},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 2, Body: "hello 2"},
+				&chat.Message{Id: 1, Body: "hello 1"},
+			},
+		},
+	})
+	if len(result.Failures) != 2 {
+		t.Fatalf("ordered messages should fail for each message in a different position: %v", result.Err())
+	}
+
+	result = Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_ServerStreamingWrongMessages",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Message:       &chat.Message{Id: 2, Body: "hello"},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "hello 1"},
+			},
+		},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "number of messages should be 1 it got 2") {
+		t.Fatalf("a different number of messages should fail: %v", result.Err())
+	}
+}
+
+func TestGRPC_ServerStreamingError(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_ServerStreamingError",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Message:       &chat.Message{Id: 3, Body: errMessage},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: errMessage + " 1"},
+			},
+			Err: status.New(codes.Unavailable, errMessage),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_ClientStreaming(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_ClientStreaming",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "Collect",
+			Messages: []interface{}{
+				&chat.Message{Body: "first"},
+				&chat.Message{Body: "second"},
+			},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 2, Body: "first, second"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_BidirectionalStreaming(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_BidirectionalStreaming",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "Echo",
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "first"},
+				&chat.Message{Id: 2, Body: "second"},
+			},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "first"},
+				&chat.Message{Id: 2, Body: "second"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_BidirectionalStreamingError(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_BidirectionalStreamingError",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "Echo",
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "first"},
+				&chat.Message{Id: 2, Body: errMessage},
+				&chat.Message{Id: 3, Body: "third"},
+			},
+		},
+		Output: expect.Output{
+			Messages: []interface{}{
+				&chat.Message{Id: 1, Body: "first"},
+			},
+			Err: status.New(codes.Unavailable, errMessage),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_StreamingInvalidMessages(t *testing.T) {
+	c, err := newStreamClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_StreamingInvalidMessages",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "List",
+			Messages:      []interface{}{&chat.Message{Id: 1}},
+		},
+	})
+	if err == nil {
+		t.Fatal("a server streaming function should not receive many messages")
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_StreamingInvalidMessages",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "Echo",
+			Messages:      []interface{}{"not a message"},
+		},
+	})
+	if err == nil {
+		t.Fatal("messages of the wrong type should not be sent")
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_StreamingInvalidMessages",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "Echo",
+			Message:       &chat.Message{Id: 1},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1},
+		},
+	})
+	if err == nil {
+		t.Fatal("the output message should not be used by streams")
+	}
+}
+
+func newStreamClient() (streamServiceClient, error) {
+	conn, err := grpc.Dial(fmt.Sprintf(":%d", grpcPort), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamClient{conn}, nil
+}
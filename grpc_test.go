@@ -6,21 +6,33 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lucasvmiguel/integration/assertion"
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
 	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 const (
-	errMessage = "ERROR"
-	grpcPort   = 9000
+	errMessage     = "ERROR"
+	slowMessage    = "SLOW"
+	invalidMessage = "INVALID"
+	grpcPort       = 9000
 )
 
 type Server struct {
@@ -28,10 +40,36 @@ type Server struct {
 }
 
 func (s *Server) SayHello(ctx context.Context, in *chat.Message) (*chat.Message, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if ids := md.Get("x-request-id"); len(ids) > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs("x-request-id", ids[0]))
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs("x-server", "chat"))
+
 	if in.Body == errMessage {
 		return nil, status.Error(codes.Unavailable, errMessage)
 	}
 
+	if in.Body == invalidMessage {
+		st, err := status.New(codes.InvalidArgument, "invalid message: body is not valid").WithDetails(
+			&errdetails.ErrorInfo{Reason: "INVALID_BODY", Domain: "chat"},
+			&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: "body", Description: "body is not valid"}}},
+		)
+		if err != nil {
+			return nil, err
+		}
+		return nil, st.Err()
+	}
+
+	if in.Body == slowMessage {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			return &chat.Message{Id: 1, Body: slowMessage}, nil
+		}
+	}
+
 	_, err := http.Get("https://jsonplaceholder.typicode.com/posts/1")
 	if err != nil {
 		return nil, fmt.Errorf("failed to call endpoint: %w", err)
@@ -55,6 +93,8 @@ func init() {
 	grpcServer := grpc.NewServer()
 
 	chat.RegisterChatServiceServer(grpcServer, &s)
+	grpcServer.RegisterService(&streamServiceDesc, &StreamServer{})
+	reflection.Register(grpcServer)
 
 	go grpcServer.Serve(lis)
 }
@@ -98,6 +138,232 @@ func TestGRPC_Successfully(t *testing.T) {
 	}
 }
 
+func TestGRPC_Metadata(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCase := &GRPCTestCase{
+		Description: "TestGRPC_Metadata",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "Hello From Client!"},
+			Metadata:      metadata.Pairs("x-request-id", "123"),
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1, Body: "Hello From the Server!", Comment: "<<PRESENCE>>"},
+			Header:  metadata.Pairs("x-request-id", "123"),
+			Trailer: metadata.Pairs("x-server", "chat"),
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "https://jsonplaceholder.typicode.com/posts/1",
+					Method: http.MethodGet,
+				},
+			},
+		},
+	}
+
+	result := Evaluate(testCase)
+	if !result.Passed() {
+		t.Fatal(result.Err())
+	}
+
+	value, err := capture(result.response, Capture{Name: "id", Header: "x-request-id"})
+	if err != nil || value != "123" {
+		t.Fatalf("header should be captured, it got '%s': %v", value, err)
+	}
+}
+
+func TestGRPC_WrongMetadata(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_WrongMetadata",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "Hello From Client!"},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1, Body: "Hello From the Server!", Comment: "<<PRESENCE>>"},
+			Header:  metadata.Pairs("x-request-id", "123"),
+			Trailer: metadata.Pairs("x-server", "other"),
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "https://jsonplaceholder.typicode.com/posts/1",
+					Method: http.MethodGet,
+				},
+			},
+		},
+	})
+
+	if len(result.Failures) != 2 {
+		t.Fatalf("header and trailer should fail, it got %v", result.Err())
+	}
+
+	if result.Failures[1].Expected != "other" || result.Failures[1].Actual != "chat" {
+		t.Fatalf("trailer failure should have the expected and actual values, it got %+v", result.Failures[1])
+	}
+}
+
+func TestGRPC_ErrNotReturned(t *testing.T) {
+	// the interceptor replies without calling the server
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return &chat.Message{Id: 1}, nil
+	}
+
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		chat.RegisterChatServiceServer(s, &Server{})
+	}, grpc.UnaryInterceptor(interceptor))
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_ErrNotReturned",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1},
+			Err:     status.New(codes.Unavailable, errMessage),
+		},
+	})
+
+	if result.Passed() || !strings.Contains(result.Err().Error(), "it got none") || strings.Contains(result.Err().Error(), "%!") {
+		t.Fatalf("it should fail because no error was returned, it got %v", result.Err())
+	}
+}
+
+func TestGRPC_Exact(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCase := &GRPCTestCase{
+		Description: "TestGRPC_Exact",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "Hello From Client!"},
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1, Body: "Hello From the Server!"},
+			Exact:   true,
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "https://jsonplaceholder.typicode.com/posts/1",
+					Method: http.MethodGet,
+				},
+			},
+		},
+	}
+
+	err = Test(testCase)
+	if err == nil {
+		t.Fatal("it should fail because the comment is not expected")
+	}
+
+	testCase.Output.IgnoreFields = []string{"comment"}
+	err = Test(testCase)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_Status(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_Status",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Body: invalidMessage},
+		},
+		Output: expect.Output{
+			Status: &expect.Status{
+				Code:            codes.InvalidArgument,
+				MessageContains: "body is not valid",
+				MessageRegex:    "^invalid message",
+				Details: []interface{}{
+					`{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [{"field": "body", "description": "<<PRESENCE>>"}]}`,
+					&errdetails.ErrorInfo{Reason: "INVALID_BODY", Domain: "<<PRESENCE>>"},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_WrongStatus(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_WrongStatus",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Body: invalidMessage},
+		},
+		Output: expect.Output{
+			Status: &expect.Status{
+				Code:         codes.InvalidArgument,
+				MessageRegex: "^body",
+				Details: []interface{}{
+					&errdetails.ErrorInfo{Reason: "OTHER"},
+					&errdetails.RetryInfo{},
+				},
+			},
+		},
+	})
+
+	if len(result.Failures) != 3 {
+		t.Fatalf("message and both details should fail, it got %v", result.Err())
+	}
+
+	if !strings.Contains(result.Failures[1].Actual, "INVALID_BODY") {
+		t.Fatalf("the details received should be in the failure, it got %s", result.Failures[1].Actual)
+	}
+
+	result = Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_WrongStatus",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Body: invalidMessage},
+		},
+		Output: expect.Output{
+			Err:    status.New(codes.InvalidArgument, "invalid message: body is not valid"),
+			Status: &expect.Status{Code: codes.InvalidArgument},
+		},
+	})
+
+	if result.Passed() || result.Failures[0].Phase != PhaseValidate {
+		t.Fatalf("error and status should not be set together, it got %v", result.Err())
+	}
+}
+
 func TestGRPC_Error(t *testing.T) {
 	c, err := client()
 	if err != nil {
@@ -165,3 +431,124 @@ func client() (chat.ChatServiceClient, error) {
 
 	return chat.NewChatServiceClient(conn), nil
 }
+
+func TestGRPC_Timeout(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_Timeout",
+		Call: call.Call{
+			ServiceClient: c,
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: slowMessage},
+			Timeout:       50 * time.Millisecond,
+		},
+		Output: expect.Output{
+			Message: &chat.Message{Id: 1, Body: slowMessage},
+		},
+	})
+
+	if result.Passed() || result.Failures[len(result.Failures)-1].Kind != KindTimeout {
+		t.Fatalf("it should fail due to timeout, it got %v", result.Err())
+	}
+
+	if result.Duration >= time.Second {
+		t.Fatalf("it should stop at the timeout, it took %s", result.Duration)
+	}
+}
+
+func TestGRPC_DynamicReflection(t *testing.T) {
+	err := Test(&GRPCTestCase{
+		Description: "TestGRPC_DynamicReflection",
+		Call: call.Call{
+			Target:  fmt.Sprintf("localhost:%d", grpcPort),
+			Method:  "chat.ChatService/SayHello",
+			Message: `{"id": 1, "body": "Hello From Client!"}`,
+		},
+		Output: expect.Output{
+			Message: `{"id": 1, "body": "Hello From the Server!", "comment": "<<PRESENCE>>"}`,
+			Trailer: metadata.Pairs("x-server", "chat"),
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "https://jsonplaceholder.typicode.com/posts/1",
+					Method: http.MethodGet,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_DynamicProtoFiles(t *testing.T) {
+	err := Test(&GRPCTestCase{
+		Description: "TestGRPC_DynamicProtoFiles",
+		Call: call.Call{
+			Target:     fmt.Sprintf("localhost:%d", grpcPort),
+			Method:     "chat.ChatService/SayHello",
+			ProtoFiles: []string{"chat.proto"},
+			Message:    map[string]interface{}{"body": errMessage},
+		},
+		Output: expect.Output{
+			Err: status.New(codes.Unavailable, errMessage),
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_DynamicDescriptorSet(t *testing.T) {
+	content, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(chat.File_chat_proto)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "chat.protoset")
+	err = os.WriteFile(path, content, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&GRPCTestCase{
+		Description: "TestGRPC_DynamicDescriptorSet",
+		Call: call.Call{
+			Target:        fmt.Sprintf("localhost:%d", grpcPort),
+			Method:        "chat.ChatService/SayHello",
+			DescriptorSet: path,
+			Message:       `{"body": "ERROR"}`,
+		},
+		Output: expect.Output{
+			Err: status.New(codes.Unavailable, errMessage),
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGRPC_DynamicInvalidMethod(t *testing.T) {
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestGRPC_DynamicInvalidMethod",
+		Call: call.Call{
+			Target:  fmt.Sprintf("localhost:%d", grpcPort),
+			Method:  "chat.ChatService/SayGoodbye",
+			Message: `{}`,
+		},
+	})
+
+	if result.Passed() || result.Failures[0].Phase != PhaseCall || !strings.Contains(result.Err().Error(), "does not have the method 'SayGoodbye'") {
+		t.Fatalf("it should fail to find the method, it got %v", result.Err())
+	}
+}
@@ -2,17 +2,23 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 
 	"github.com/jarcoal/httpmock"
-	"github.com/kinbiko/jsonassert"
 	"github.com/lucasvmiguel/integration/assertion"
 	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/contract"
 	"github.com/lucasvmiguel/integration/expect"
 	"github.com/lucasvmiguel/integration/internal/utils"
+	"github.com/lucasvmiguel/integration/matcher"
+	"github.com/lucasvmiguel/integration/mock"
 )
 
 // HTTPTestCase describes a HTTP test case that will run
@@ -30,104 +36,198 @@ type HTTPTestCase struct {
 
 	// Assertions that will run in test case
 	Assertions []assertion.Assertion
+
+	// Mock is the registry used by the HTTP assertions of the test case (eg: mock.NewTransport()).
+	// If it's nil, the HTTP assertions use the global httpmock transport, which can't run in parallel.
+	Mock mock.Registry
+
+	// Handler serves the request in memory instead of sending it over the network.
+	// The request URL can be only a path (eg: /todos).
+	// eg: router
+	Handler http.Handler
+
+	// Client sends the request, it can't be set with ClientOptions.
+	// eg: server.Client()
+	Client *http.Client
+
+	// ClientOptions sets up the HTTP client that sends the request (eg: certificates, redirects and cookies)
+	ClientOptions call.ClientOptions
+
+	// Session is shared by test cases to keep the cookies received and send default headers.
+	// eg: &integration.Session{}
+	Session *Session
+
+	// Contract validates the request and the response against an OpenAPI 3 spec,
+	// which records the operation in its coverage. It should be shared by the test cases.
+	// eg: contract.Load("openapi.yaml")
+	Contract *contract.Spec
 }
 
+// handlerHost is the host of the requests served by a Handler whose URL is only a path
+const handlerHost = "http://localhost"
+
 // Test runs an HTTP test case
 func (t *HTTPTestCase) Test() error {
+	return t.TestContext(context.Background())
+}
+
+// TestContext runs an HTTP test case, the context is used to cancel the request
+func (t *HTTPTestCase) TestContext(ctx context.Context) error {
+	return t.evaluate(ctx).Err()
+}
+
+func (t *HTTPTestCase) evaluate(ctx context.Context) *Result {
+	result := newResult(t.Description, "HTTP")
+	defer result.finish()
+
 	err := t.validate()
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to validate test case"))
+		return result.fail(PhaseValidate, "failed to validate test case", err)
 	}
 
-	if assertion.AnyHTTP(t.Assertions) {
+	assertions := assertion.WithMock(t.Assertions, t.Mock)
+
+	if assertion.AnyGlobalHTTP(assertions) {
 		httpmock.Activate()
 		defer httpmock.DeactivateAndReset()
 
 		httpmock.RegisterResponder(t.method(), t.Request.URL, httpmock.InitialTransport.RoundTrip)
 	}
 
-	err = t.setupAssertions()
+	err = t.setupAssertions(assertions)
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to setup assertions"))
+		return result.fail(PhaseSetup, "failed to setup assertions", err)
 	}
 
-	resp, err := t.call()
+	// the timeout limits the call only, the assertions run with the context of the test case
+	callCtx, cancel := withTimeout(ctx, t.Request.Timeout)
+	defer cancel()
+
+	resp, err := t.call(callCtx)
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to call HTTP endpoint"))
+		return result.fail(PhaseCall, "failed to call HTTP endpoint", err)
 	}
 
-	err = t.assert(resp)
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to assert HTTP response"))
+		result.fail(PhaseAssert, "failed to assert HTTP response", fmt.Errorf("failed to read response body: %w", err))
+	} else {
+		result.response = &response{header: resp.Header, body: respBody}
+
+		for _, err := range t.assert(resp, respBody) {
+			result.fail(PhaseAssert, "failed to assert HTTP response", err)
+		}
 	}
 
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Assert()
-			if err != nil {
-				return errors.New(errString(err, t.Description, "failed to assert"))
-			}
+	for i, a := range assertions {
+		err := assertion.AssertContext(ctx, a)
+		if err != nil {
+			result.failAssertion(i+1, err)
 		}
 	}
 
-	return nil
+	return result
 }
 
-func (t *HTTPTestCase) setupAssertions() error {
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Setup()
-			if err != nil {
-				return fmt.Errorf("failed to setup assertion: %w", err)
-			}
+func (t *HTTPTestCase) setupAssertions(assertions []assertion.Assertion) error {
+	for _, assertion := range assertions {
+		err := assertion.Setup()
+		if err != nil {
+			return fmt.Errorf("failed to setup assertion: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func (t *HTTPTestCase) assert(resp *http.Response) error {
-	defer resp.Body.Close()
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
-	}
-
+// assert returns all differences between the response received and the expected one
+func (t *HTTPTestCase) assert(resp *http.Response, respBody []byte) []error {
 	respBodyString := string(respBody)
 
+	errs := []error{}
+
 	if resp.StatusCode != t.Response.StatusCode {
-		return fmt.Errorf("response status code should be %d it got %d", t.Response.StatusCode, resp.StatusCode)
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("response status code should be %d it got %d", t.Response.StatusCode, resp.StatusCode),
+			expected: strconv.Itoa(t.Response.StatusCode),
+			actual:   strconv.Itoa(resp.StatusCode),
+		})
 	}
 
+	// the body is not compared if only its fields or schema are expected
+	assertBody := t.Response.Body != "" || len(t.Response.Fields) == 0 && t.Response.Schema == nil
+
 	if utils.IsJSON(t.Response.Body) {
-		je := utils.JsonError{}
-		jsonassert.New(&je).Assertf(respBodyString, t.Response.Body)
-		if je.Err != nil {
-			return fmt.Errorf("response body is a JSON. response body does not match: %v", je.Err.Error())
+		err := matcher.JSON(respBodyString, t.Response.Body)
+		if err != nil {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response body is a JSON. response body does not match: %v", err),
+				expected: t.Response.Body,
+				actual:   respBodyString,
+				diff:     bodyDiff(t.Response.Body, respBodyString),
+			})
 		}
-	} else {
+	} else if matcher.IsPlaceholder(t.Response.Body) {
+		err := matcher.Match(respBodyString, t.Response.Body)
+		if err != nil {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response body does not match %s: %w", t.Response.Body, err),
+				expected: t.Response.Body,
+				actual:   respBodyString,
+			})
+		}
+	} else if assertBody {
 		if respBodyString != t.Response.Body {
-			return fmt.Errorf("response body is a regular string. response body should be '%s' it got '%s'", t.Response.Body, respBodyString)
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response body is a regular string. response body should be '%s' it got '%s'", t.Response.Body, respBodyString),
+				expected: t.Response.Body,
+				actual:   respBodyString,
+				diff:     bodyDiff(t.Response.Body, respBodyString),
+			})
 		}
 	}
 
 	for key, values := range t.Response.Header {
 		respHeader := resp.Header.Get(key)
 		if respHeader != values[0] {
-			return fmt.Errorf("response header should be '%s' it got '%s'", values[0], respHeader)
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response header should be '%s' it got '%s'", values[0], respHeader),
+				expected: values[0],
+				actual:   respHeader,
+			})
 		}
 	}
 
-	return nil
+	errs = append(errs, assertFields(respBody, t.Response.Fields)...)
+
+	if t.Response.Schema != nil {
+		err := assertSchema("response body", t.Response.Schema, respBody)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, t.assertCookies(resp.Cookies())...)
+
+	if t.Contract != nil {
+		errs = append(errs, t.assertContract(resp, respBody)...)
+	}
+
+	return errs
 }
 
-func (t *HTTPTestCase) call() (*http.Response, error) {
-	req, err := t.createHTTPRequest()
+func (t *HTTPTestCase) call(ctx context.Context) (*http.Response, error) {
+	req, err := t.createHTTPRequest(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create http request: %w", err)
 	}
 
-	client := &http.Client{}
+	client, err := t.client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call endpoint: %w", err)
@@ -135,25 +235,125 @@ func (t *HTTPTestCase) call() (*http.Response, error) {
 	return resp, nil
 }
 
-func (t *HTTPTestCase) createHTTPRequest() (*http.Request, error) {
+func (t *HTTPTestCase) createHTTPRequest(ctx context.Context) (*http.Request, error) {
 	var reqBody io.Reader
 	reqBodyString := t.Request.Body
+	contentType := ""
 
-	if reqBodyString == "" {
+	if t.Request.Payload != nil {
+		content, payloadContentType, err := t.Request.Payload.Encode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request payload: %w", err)
+		}
+		reqBody = bytes.NewReader(content)
+		contentType = payloadContentType
+	} else if reqBodyString == "" {
 		reqBody = nil
 	} else {
 		reqBody = bytes.NewBufferString(t.Request.Body)
 	}
 
-	req, err := http.NewRequest(t.method(), t.Request.URL, reqBody)
+	url := t.Request.URL
+	if t.Handler != nil && strings.HasPrefix(url, "/") {
+		url = handlerHost + url
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method(), url, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create a new http request: %w", err)
 	}
-	req.Header = t.Request.Header
+	req.Header = t.header()
+
+	// the content type of the payload is set, unless it's in the header
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if t.Request.Auth != nil {
+		authorization, err := t.Request.Auth.Authorization(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get authorization: %w", err)
+		}
+		req.Header.Set("Authorization", authorization)
+	}
 
 	return req, nil
 }
 
+// handlerTransport serves the requests with a handler in memory
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (h handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.RequestURI = req.URL.RequestURI()
+	req.RemoteAddr = "127.0.0.1:0"
+	if req.Body == nil {
+		req.Body = http.NoBody
+	}
+
+	recorder := httptest.NewRecorder()
+	panicked := make(chan interface{}, 1)
+	go func() {
+		defer func() { panicked <- recover() }()
+		h.handler.ServeHTTP(recorder, req)
+	}()
+
+	select {
+	case p := <-panicked:
+		if p != nil {
+			return nil, fmt.Errorf("handler panicked: %v", p)
+		}
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	resp := recorder.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// header returns the header of the request over the default header of the session.
+// It's a copy, so the client (eg: a cookie jar) doesn't change the one of the test case.
+func (t *HTTPTestCase) header() http.Header {
+	header := http.Header{}
+	for _, source := range []http.Header{t.sessionHeader(), t.Request.Header} {
+		for key, values := range source {
+			header.Del(key)
+			for _, value := range values {
+				header.Add(key, value)
+			}
+		}
+	}
+	return header
+}
+
+func (t *HTTPTestCase) sessionHeader() http.Header {
+	if t.Session == nil {
+		return nil
+	}
+	return t.Session.Header
+}
+
+func (t *HTTPTestCase) interpolate(vars map[string]string) (Tester, error) {
+	i := interpolation{vars: vars}
+
+	interpolated := *t
+	interpolated.Request.URL = i.string(t.Request.URL)
+	interpolated.Request.Header = i.header(t.Request.Header)
+	interpolated.Request.Auth = i.auth(t.Request.Auth)
+	interpolated.Request.Body = i.string(t.Request.Body)
+	interpolated.Request.Payload = i.payload(t.Request.Payload)
+	interpolated.Response.Header = i.header(t.Response.Header)
+	interpolated.Response.Body = i.string(t.Response.Body)
+	interpolated.Response.Cookies = i.cookies(t.Response.Cookies)
+	interpolated.Response.Fields = i.fields(t.Response.Fields)
+	interpolated.Assertions = i.assertions(t.Assertions)
+
+	return &interpolated, i.err
+}
+
 func (t *HTTPTestCase) method() string {
 	method := t.Request.Method
 	if method == "" {
@@ -171,5 +371,38 @@ func (t *HTTPTestCase) validate() error {
 		return errors.New("response status code is required")
 	}
 
+	if t.Request.Body != "" && t.Request.Payload != nil {
+		return errors.New("request body and payload can't be set together")
+	}
+
+	if t.Client != nil && t.ClientOptions != (call.ClientOptions{}) {
+		return errors.New("client and client options can't be set together")
+	}
+
+	if t.Session != nil && t.ClientOptions.Jar != nil {
+		return errors.New("session and client options jar can't be set together")
+	}
+
+	if t.Response.Schema != nil {
+		_, err := compileSchema(t.Response.Schema)
+		if err != nil {
+			return fmt.Errorf("response schema is invalid: %w", err)
+		}
+	}
+
+	for i, field := range t.Response.Fields {
+		err := validateField(field)
+		if err != nil {
+			return fmt.Errorf("response field %d is invalid: %w", i+1, err)
+		}
+	}
+
+	for i, cookie := range t.Response.Cookies {
+		err := validateCookie(cookie)
+		if err != nil {
+			return fmt.Errorf("response cookie %d is invalid: %w", i+1, err)
+		}
+	}
+
 	return nil
 }
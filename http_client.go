@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/lucasvmiguel/integration/call"
+)
+
+// client returns the HTTP client that sends the request, with the handler and the session jar if they are set
+func (t *HTTPTestCase) client() (*http.Client, error) {
+	client := t.Client
+	if client == nil {
+		var err error
+		client, err = newHTTPClient(t.ClientOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.Handler == nil && t.Session == nil {
+		return client, nil
+	}
+
+	// the client is copied, so the one of the test case isn't changed
+	copied := *client
+	if t.Handler != nil {
+		copied.Transport = handlerTransport{handler: t.Handler}
+	}
+	if t.Session != nil {
+		copied.Jar = t.Session.Jar()
+	}
+
+	return &copied, nil
+}
+
+// newHTTPClient creates a HTTP client with the options.
+// The default transport is used if no TLS or proxy option is set, so the global httpmock can still intercept the requests.
+func newHTTPClient(options call.ClientOptions) (*http.Client, error) {
+	client := &http.Client{
+		Jar:           options.Jar,
+		CheckRedirect: checkRedirect(options),
+	}
+
+	if options.CAFile == "" && options.CertFile == "" && options.KeyFile == "" && !options.InsecureSkipVerify && options.Proxy == "" {
+		return client, nil
+	}
+
+	transport := defaultTransport()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: options.InsecureSkipVerify}
+
+	if options.CAFile != "" {
+		pool, err := certPool(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	if options.CertFile != "" || options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if options.Proxy != "" {
+		proxyURL, err := url.Parse(options.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	client.Transport = transport
+	return client, nil
+}
+
+// certPool returns the system certificate authorities with the ones of the PEM file
+func certPool(path string) (*x509.CertPool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, errors.New("failed to read CA file: no certificate found")
+	}
+
+	return pool, nil
+}
+
+// checkRedirect returns the redirect policy of the options, nil is the default policy of 10 redirects
+func checkRedirect(options call.ClientOptions) func(req *http.Request, via []*http.Request) error {
+	if options.NoRedirects {
+		return func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	if options.MaxRedirects == 0 {
+		return nil
+	}
+
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) > options.MaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", options.MaxRedirects)
+		}
+		return nil
+	}
+}
+
+// defaultTransport returns a copy of the default transport.
+// httpmock replaces the default transport while global HTTP assertions run, so the one it replaced is copied.
+func defaultTransport() *http.Transport {
+	transport, ok := httpmock.InitialTransport.(*http.Transport)
+	if !ok {
+		return &http.Transport{Proxy: http.ProxyFromEnvironment}
+	}
+	return transport.Clone()
+}
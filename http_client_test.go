@@ -0,0 +1,293 @@
+package integration
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	goHTTP "net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+func okHandler(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+	w.Write([]byte("ok"))
+}
+
+// writePEM writes the blocks in a PEM file of the test temp dir
+func writePEM(t *testing.T, name string, blocks ...*pem.Block) string {
+	path := filepath.Join(t.TempDir(), name)
+	content := []byte{}
+	for _, block := range blocks {
+		content = append(content, pem.EncodeToMemory(block)...)
+	}
+
+	err := os.WriteFile(path, content, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// clientCertificate creates a self-signed client certificate and returns its cert and key files
+func clientCertificate(t *testing.T) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cert,
+		writePEM(t, "client.pem", &pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		writePEM(t, "client-key.pem", &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestHTTPClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(goHTTP.HandlerFunc(okHandler))
+	defer server.Close()
+
+	caFile := writePEM(t, "ca.pem", &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	tests := []struct {
+		name          string
+		client        *goHTTP.Client
+		clientOptions call.ClientOptions
+	}{
+		{name: "client", client: server.Client()},
+		{name: "ca file", clientOptions: call.ClientOptions{CAFile: caFile}},
+		{name: "insecure skip verify", clientOptions: call.ClientOptions{InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		err := Test(&HTTPTestCase{
+			Description:   "TestHTTPClient_TLS " + tt.name,
+			Client:        tt.client,
+			ClientOptions: tt.clientOptions,
+			Request:       call.Request{URL: server.URL},
+			Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHTTPClient_TLS unknown authority",
+		Request:     call.Request{URL: server.URL},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "certificate") {
+		t.Fatalf("it should fail due to the self-signed certificate, it got %v", result.Err())
+	}
+}
+
+func TestHTTPClient_MTLS(t *testing.T) {
+	cert, certFile, keyFile := clientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(goHTTP.HandlerFunc(okHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHTTPClient_MTLS",
+		ClientOptions: call.ClientOptions{
+			CertFile:           certFile,
+			KeyFile:            keyFile,
+			InsecureSkipVerify: true,
+		},
+		Request:  call.Request{URL: server.URL},
+		Response: expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description:   "TestHTTPClient_MTLS without certificate",
+		ClientOptions: call.ClientOptions{InsecureSkipVerify: true},
+		Request:       call.Request{URL: server.URL},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail without the client certificate")
+	}
+}
+
+func TestHTTPClient_Redirects(t *testing.T) {
+	mux := goHTTP.NewServeMux()
+	mux.HandleFunc("/first", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		goHTTP.Redirect(w, req, "/second", goHTTP.StatusFound)
+	})
+	mux.HandleFunc("/second", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		goHTTP.Redirect(w, req, "/last", goHTTP.StatusFound)
+	})
+	mux.HandleFunc("/last", okHandler)
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHTTPClient_Redirects follow",
+		Request:     call.Request{URL: server.URL + "/first"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&HTTPTestCase{
+		Description:   "TestHTTPClient_Redirects no redirects",
+		ClientOptions: call.ClientOptions{NoRedirects: true},
+		Request:       call.Request{URL: server.URL + "/first"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusFound,
+			Header:     goHTTP.Header{"Location": []string{"/second"}},
+			Body:       "<a href=\"/second\">Found</a>.\n\n",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&HTTPTestCase{
+		Description:   "TestHTTPClient_Redirects in memory",
+		Handler:       mux,
+		ClientOptions: call.ClientOptions{MaxRedirects: 2},
+		Request:       call.Request{URL: "/first"},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description:   "TestHTTPClient_Redirects max redirects",
+		ClientOptions: call.ClientOptions{MaxRedirects: 1},
+		Request:       call.Request{URL: server.URL + "/first"},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "ok"},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "stopped after 1 redirects") {
+		t.Fatalf("it should fail due to the max redirects, it got %v", result.Err())
+	}
+}
+
+func TestHTTPClient_Jar(t *testing.T) {
+	mux := goHTTP.NewServeMux()
+	mux.HandleFunc("/login", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		goHTTP.SetCookie(w, &goHTTP.Cookie{Name: "session", Value: "abc", Path: "/"})
+		w.WriteHeader(goHTTP.StatusNoContent)
+	})
+	mux.HandleFunc("/me", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			w.WriteHeader(goHTTP.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(cookie.Value))
+	})
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&Scenario{
+		Description: "TestHTTPClient_Jar",
+		Steps: []Step{
+			{Test: &HTTPTestCase{
+				Handler:       mux,
+				ClientOptions: call.ClientOptions{Jar: jar},
+				Request:       call.Request{URL: "/login", Method: goHTTP.MethodPost},
+				Response:      expect.Response{StatusCode: goHTTP.StatusNoContent},
+			}},
+			{Test: &HTTPTestCase{
+				Handler:       mux,
+				ClientOptions: call.ClientOptions{Jar: jar},
+				Request:       call.Request{URL: "/me"},
+				Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "abc"},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPClient_Proxy(t *testing.T) {
+	proxy := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		w.Write([]byte("proxied " + req.URL.String()))
+	}))
+	defer proxy.Close()
+
+	err := Test(&HTTPTestCase{
+		Description:   "TestHTTPClient_Proxy",
+		ClientOptions: call.ClientOptions{Proxy: proxy.URL},
+		Request:       call.Request{URL: "http://example.com/posts"},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK, Body: "proxied http://example.com/posts"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPClient_Invalid(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description:   "TestHTTPClient_Invalid",
+		Client:        &goHTTP.Client{},
+		ClientOptions: call.ClientOptions{NoRedirects: true},
+		Request:       call.Request{URL: "http://localhost:8080"},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK},
+	})
+	if result.Passed() || result.Failures[0].Phase != PhaseValidate {
+		t.Fatalf("it should fail validation, it got %v", result.Err())
+	}
+
+	result = Evaluate(&HTTPTestCase{
+		Description:   "TestHTTPClient_Invalid CA file",
+		ClientOptions: call.ClientOptions{CAFile: "testdata/not-found.pem"},
+		Request:       call.Request{URL: "http://localhost:8080"},
+		Response:      expect.Response{StatusCode: goHTTP.StatusOK},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "failed to read CA file") {
+		t.Fatalf("it should fail due to the CA file, it got %v", result.Err())
+	}
+}
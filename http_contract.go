@@ -0,0 +1,17 @@
+package integration
+
+import (
+	"errors"
+	"net/http"
+)
+
+// assertContract returns the violations of the contract by the request sent and the response received
+func (t *HTTPTestCase) assertContract(resp *http.Response, respBody []byte) []error {
+	// the request of the response is the last one sent, after following the redirects
+	req := resp.Request
+	if req == nil {
+		return []error{errors.New("failed to validate contract: the request of the response is unknown")}
+	}
+
+	return t.Contract.Validate(req.Context(), req, resp, respBody)
+}
@@ -0,0 +1,124 @@
+package integration
+
+import (
+	goHTTP "net/http"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/contract"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+// postsAPI serves the posts of the contract in testdata/openapi.yaml, breaking it for the post 2
+func postsAPI(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+	switch {
+	case req.Method == goHTTP.MethodPost && req.URL.Path == "/v1/posts":
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Location", "/v1/posts/1")
+		w.WriteHeader(goHTTP.StatusCreated)
+		w.Write([]byte(`{"id": 1, "title": "foo"}`))
+	case req.URL.Path == "/v1/posts/1":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "foo"}`))
+	case req.URL.Path == "/v1/posts/2":
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(goHTTP.StatusTeapot)
+	default:
+		goHTTP.NotFound(w, req)
+	}
+}
+
+func TestHTTPContract(t *testing.T) {
+	spec, err := contract.Load("testdata/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&Scenario{
+		Description: "TestHTTPContract",
+		Steps: []Step{
+			{Test: &HTTPTestCase{
+				Handler:  goHTTP.HandlerFunc(postsAPI),
+				Contract: spec,
+				Request: call.Request{
+					URL:    "/v1/posts",
+					Method: goHTTP.MethodPost,
+					Header: goHTTP.Header{"Content-Type": []string{"application/json"}},
+					Body:   `{"title": "foo"}`,
+				},
+				Response: expect.Response{StatusCode: goHTTP.StatusCreated, Body: `{"id": 1, "title": "foo"}`},
+			}},
+			{Test: &HTTPTestCase{
+				Handler:  goHTTP.HandlerFunc(postsAPI),
+				Contract: spec,
+				Request:  call.Request{URL: "/v1/posts/1"},
+				Response: expect.Response{StatusCode: goHTTP.StatusOK, Body: `{"id": 1, "title": "foo"}`},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	coverage := spec.Coverage()
+	if coverage.Covered() != 2 || len(coverage.Operations) != 3 {
+		t.Fatalf("it should cover 2 of 3 operations, it got %s", coverage)
+	}
+}
+
+func TestHTTPContract_Violations(t *testing.T) {
+	spec, err := contract.Load("testdata/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		request    call.Request
+		statusCode int
+		body       string
+		violation  string
+	}{
+		{
+			name:       "request body",
+			request:    call.Request{URL: "/v1/posts", Method: goHTTP.MethodPost, Payload: call.JSON{Value: map[string]string{"title": ""}}},
+			statusCode: goHTTP.StatusCreated,
+			body:       `{"id": 1, "title": "foo"}`,
+			violation:  "request of POST /posts does not match the contract: request body has an error: doesn't match schema #/components/schemas/NewPost: /title: minimum string length is 1",
+		},
+		{
+			name:       "path param",
+			request:    call.Request{URL: "/v1/posts/foo"},
+			statusCode: goHTTP.StatusNotFound,
+			body:       "404 page not found\n",
+			violation:  "parameter \"id\" in path has an error",
+		},
+		{
+			name:       "status",
+			request:    call.Request{URL: "/v1/posts/2"},
+			statusCode: goHTTP.StatusTeapot,
+			violation:  "response of GET /posts/{id} does not match the contract: status is not supported",
+		},
+		{
+			name:       "operation",
+			request:    call.Request{URL: "/v1/posts/1", Method: goHTTP.MethodPut},
+			statusCode: goHTTP.StatusOK,
+			body:       `{"id": 1, "title": "foo"}`,
+			violation:  "operation PUT /v1/posts/1 is not in the contract",
+		},
+	}
+
+	for _, tt := range tests {
+		result := Evaluate(&HTTPTestCase{
+			Description: "TestHTTPContract_Violations " + tt.name,
+			Handler:     goHTTP.HandlerFunc(postsAPI),
+			Contract:    spec,
+			Request:     tt.request,
+			Response:    expect.Response{StatusCode: tt.statusCode, Body: tt.body},
+		})
+		if result.Passed() || len(result.Failures) != 1 || !strings.Contains(result.Err().Error(), tt.violation) {
+			t.Fatalf("%s: it should fail with '%s', it got %v", tt.name, tt.violation, result.Err())
+		}
+	}
+}
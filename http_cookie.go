@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+// cookieExpirationTolerance is the difference accepted between when a cookie expires and when it was expected to
+const cookieExpirationTolerance = 5 * time.Second
+
+// sameSiteNames are the names of the SameSite attribute values
+var sameSiteNames = map[http.SameSite]string{
+	http.SameSiteDefaultMode: "",
+	http.SameSiteStrictMode:  "Strict",
+	http.SameSiteLaxMode:     "Lax",
+	http.SameSiteNoneMode:    "None",
+}
+
+// assertCookies returns all differences between the cookies received and the expected ones
+func (t *HTTPTestCase) assertCookies(cookies []*http.Cookie) []error {
+	errs := []error{}
+
+	for _, expected := range t.Response.Cookies {
+		cookie := findCookie(cookies, expected.Name)
+		if cookie == nil {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response cookie '%s' should be set", expected.Name),
+				expected: expected.Name,
+				actual:   cookieNames(cookies),
+			})
+			continue
+		}
+
+		errs = append(errs, assertCookie(expected, cookie)...)
+	}
+
+	return errs
+}
+
+// assertCookie returns all differences between the attributes of the cookie and the expected ones
+func assertCookie(expected expect.Cookie, cookie *http.Cookie) []error {
+	errs := []error{}
+
+	attributes := []struct {
+		name     string
+		expected string
+		actual   string
+		assert   bool
+	}{
+		{"value", expected.Value, cookie.Value, expected.Value != ""},
+		{"path", expected.Path, cookie.Path, expected.Path != ""},
+		{"domain", expected.Domain, cookie.Domain, expected.Domain != ""},
+		{"HttpOnly", "true", fmt.Sprint(cookie.HttpOnly), expected.HttpOnly},
+		{"Secure", "true", fmt.Sprint(cookie.Secure), expected.Secure},
+		// SameSite is case insensitive (eg: strict is Strict)
+		{"SameSite", sameSiteName(expected.SameSite), sameSiteNames[cookie.SameSite], expected.SameSite != ""},
+	}
+
+	for _, attribute := range attributes {
+		if !attribute.assert || attribute.expected == attribute.actual {
+			continue
+		}
+
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("response cookie '%s' %s should be '%s' it got '%s'", expected.Name, attribute.name, attribute.expected, attribute.actual),
+			expected: attribute.expected,
+			actual:   attribute.actual,
+		})
+	}
+
+	if expected.ValueRegex != "" && !regexp.MustCompile(expected.ValueRegex).MatchString(cookie.Value) {
+		errs = append(errs, &mismatchError{
+			err:      fmt.Errorf("response cookie '%s' value should match '%s' it got '%s'", expected.Name, expected.ValueRegex, cookie.Value),
+			expected: expected.ValueRegex,
+			actual:   cookie.Value,
+		})
+	}
+
+	if expected.ExpiresIn != 0 {
+		expiresIn := cookieExpiresIn(cookie)
+		difference := expiresIn - expected.ExpiresIn
+		if difference < -cookieExpirationTolerance || difference > cookieExpirationTolerance {
+			errs = append(errs, &mismatchError{
+				err:      fmt.Errorf("response cookie '%s' should expire in %v it expires in %v", expected.Name, expected.ExpiresIn, expiresIn),
+				expected: expected.ExpiresIn.String(),
+				actual:   expiresIn.String(),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateCookie returns why an expected cookie is invalid
+func validateCookie(cookie expect.Cookie) error {
+	if cookie.Name == "" {
+		return errors.New("name is required")
+	}
+
+	if cookie.ValueRegex != "" {
+		_, err := regexp.Compile(cookie.ValueRegex)
+		if err != nil {
+			return fmt.Errorf("failed to compile value regex: %w", err)
+		}
+	}
+
+	if cookie.SameSite != "" && sameSiteName(cookie.SameSite) == "" {
+		return fmt.Errorf("same site '%s' should be Strict, Lax or None", cookie.SameSite)
+	}
+
+	return nil
+}
+
+// sameSiteName returns the name of the SameSite attribute value as written in sameSiteNames, or empty if it's unknown
+func sameSiteName(value string) string {
+	for _, name := range sameSiteNames {
+		if name != "" && strings.EqualFold(name, value) {
+			return name
+		}
+	}
+	return ""
+}
+
+// findCookie returns the last cookie with the name, as it overrides the previous ones
+func findCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	var found *http.Cookie
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			found = cookie
+		}
+	}
+	return found
+}
+
+func cookieNames(cookies []*http.Cookie) string {
+	names := []string{}
+	for _, cookie := range cookies {
+		names = append(names, cookie.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// cookieExpiresIn returns when the cookie expires, 0 if it's a session cookie and negative if it's deleted
+func cookieExpiresIn(cookie *http.Cookie) time.Duration {
+	switch {
+	case cookie.MaxAge > 0:
+		return time.Duration(cookie.MaxAge) * time.Second
+	case cookie.MaxAge < 0:
+		return -time.Second
+	case !cookie.Expires.IsZero():
+		return time.Until(cookie.Expires).Round(time.Second)
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+package integration
+
+import (
+	goHTTP "net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+func cookieHandler(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+	goHTTP.SetCookie(w, &goHTTP.Cookie{
+		Name:     "session",
+		Value:    "0a1b2c3d",
+		Path:     "/",
+		MaxAge:   3600,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: goHTTP.SameSiteStrictMode,
+	})
+	goHTTP.SetCookie(w, &goHTTP.Cookie{Name: "theme", Value: "dark", Expires: time.Now().Add(24 * time.Hour)})
+	goHTTP.SetCookie(w, &goHTTP.Cookie{Name: "old", MaxAge: -1})
+}
+
+func TestHTTPCookies(t *testing.T) {
+	err := Test(&HTTPTestCase{
+		Description: "TestHTTPCookies",
+		Handler:     goHTTP.HandlerFunc(cookieHandler),
+		Request:     call.Request{URL: "/"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Cookies: []expect.Cookie{
+				{
+					Name:       "session",
+					ValueRegex: "^[a-f0-9]{8}$",
+					Path:       "/",
+					HttpOnly:   true,
+					Secure:     true,
+					SameSite:   "strict",
+					ExpiresIn:  time.Hour,
+				},
+				{Name: "theme", Value: "dark", ExpiresIn: 24 * time.Hour},
+				{Name: "old"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPCookies_Wrong(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHTTPCookies_Wrong",
+		Handler:     goHTTP.HandlerFunc(cookieHandler),
+		Request:     call.Request{URL: "/"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Cookies: []expect.Cookie{
+				{Name: "session", Value: "foo", SameSite: "Lax", ExpiresIn: time.Minute},
+				{Name: "theme", ValueRegex: "^light$", HttpOnly: true, Secure: true},
+				{Name: "token"},
+			},
+		},
+	})
+
+	expected := []string{
+		"response cookie 'session' value should be 'foo' it got '0a1b2c3d'",
+		"response cookie 'session' SameSite should be 'Lax' it got 'Strict'",
+		"response cookie 'session' should expire in 1m0s it expires in 1h0m0s",
+		"response cookie 'theme' HttpOnly should be 'true' it got 'false'",
+		"response cookie 'theme' Secure should be 'true' it got 'false'",
+		"response cookie 'theme' value should match '^light$' it got 'dark'",
+		"response cookie 'token' should be set",
+	}
+	if len(result.Failures) != len(expected) {
+		t.Fatalf("it should have %d failures, it got %v", len(expected), result.Err())
+	}
+	for _, message := range expected {
+		if !strings.Contains(result.Err().Error(), message) {
+			t.Fatalf("it should fail with '%s', it got %v", message, result.Err())
+		}
+	}
+}
+
+func TestHTTPCookies_Invalid(t *testing.T) {
+	tests := map[string]expect.Cookie{
+		"no name":          {Value: "foo"},
+		"invalid regex":    {Name: "session", ValueRegex: "("},
+		"invalid samesite": {Name: "session", SameSite: "foo"},
+	}
+
+	for name, cookie := range tests {
+		result := Evaluate(&HTTPTestCase{
+			Description: "TestHTTPCookies_Invalid",
+			Handler:     goHTTP.HandlerFunc(cookieHandler),
+			Request:     call.Request{URL: "/"},
+			Response:    expect.Response{StatusCode: goHTTP.StatusOK, Cookies: []expect.Cookie{cookie}},
+		})
+		if result.Passed() || result.Failures[0].Phase != PhaseValidate {
+			t.Fatalf("%s: it should fail validation, it got %v", name, result.Err())
+		}
+	}
+}
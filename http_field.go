@@ -0,0 +1,328 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/jsonpath"
+)
+
+// operatorDescriptions describe the operators in the failures (eg: should be greater than 0)
+var operatorDescriptions = map[expect.Operator]string{
+	expect.OperatorEquals:      "be equal to",
+	expect.OperatorNotEquals:   "be different from",
+	expect.OperatorExists:      "exist",
+	expect.OperatorAbsent:      "be absent",
+	expect.OperatorLength:      "have length",
+	expect.OperatorGreaterThan: "be greater than",
+	expect.OperatorLessThan:    "be less than",
+	expect.OperatorRegex:       "match",
+	expect.OperatorContains:    "contain",
+	expect.OperatorOneOf:       "be one of",
+}
+
+// assertFields returns all differences between the fields of the JSON body and the expected ones
+func assertFields(body []byte, fields []expect.Field) []error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	document, err := decodeJSON(body)
+	if err != nil {
+		return []error{fmt.Errorf("response body is not a JSON, its fields can't be asserted: %w", err)}
+	}
+
+	errs := []error{}
+	for _, field := range fields {
+		err := assertField(document, field)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// assertField returns why the field of the document doesn't match the expected one, or nil if it matches
+func assertField(document interface{}, field expect.Field) error {
+	operator := fieldOperator(field)
+	expected, err := normalizeJSON(field.Value)
+	if err != nil {
+		return fmt.Errorf("response field '%s' value can't be marshaled to JSON: %w", field.Path, err)
+	}
+	expectedJSON := jsonText(expected)
+
+	value, err := jsonpath.Get(document, field.Path)
+	if err != nil && !errors.Is(err, jsonpath.ErrNotFound) {
+		return err
+	}
+	found := err == nil
+
+	switch {
+	case operator == expect.OperatorExists && found, operator == expect.OperatorAbsent && !found:
+		return nil
+	case operator == expect.OperatorExists:
+		return &mismatchError{
+			err:      fmt.Errorf("response field '%s' should exist: %v", field.Path, err),
+			expected: "exists",
+			actual:   "absent",
+		}
+	case operator == expect.OperatorAbsent:
+		return &mismatchError{
+			err:      fmt.Errorf("response field '%s' should be absent it got %s", field.Path, jsonText(value)),
+			expected: "absent",
+			actual:   jsonText(value),
+		}
+	case !found:
+		return &mismatchError{
+			err:      fmt.Errorf("response field '%s' should %s %s it is absent: %v", field.Path, operatorDescriptions[operator], expectedJSON, err),
+			expected: expectedJSON,
+			actual:   "absent",
+		}
+	}
+
+	actual := value
+	if operator == expect.OperatorLength {
+		length, ok := fieldLength(value)
+		if !ok {
+			return fmt.Errorf("response field '%s' should be a list, object or string to have a length, it got %s", field.Path, jsonText(value))
+		}
+		actual = json.Number(strconv.Itoa(length))
+	}
+
+	ok, err := compareField(operator, actual, expected)
+	if err != nil {
+		return fmt.Errorf("response field '%s' %w", field.Path, err)
+	}
+
+	if !ok {
+		return &mismatchError{
+			err:      fmt.Errorf("response field '%s' should %s %s it got %s", field.Path, operatorDescriptions[operator], expectedJSON, jsonText(actual)),
+			expected: expectedJSON,
+			actual:   jsonText(actual),
+		}
+	}
+
+	return nil
+}
+
+// compareField compares a field with the expected value, both decoded from JSON
+func compareField(operator expect.Operator, value, expected interface{}) (bool, error) {
+	switch operator {
+	case expect.OperatorEquals, expect.OperatorLength:
+		return equalJSON(value, expected), nil
+	case expect.OperatorNotEquals:
+		return !equalJSON(value, expected), nil
+	case expect.OperatorGreaterThan, expect.OperatorLessThan:
+		number, ok := value.(json.Number)
+		if !ok {
+			return false, fmt.Errorf("should be a number to be compared, it got %s", jsonText(value))
+		}
+
+		comparison, ok := compareNumbers(number, expected.(json.Number))
+		if !ok {
+			return false, fmt.Errorf("should be a number to be compared, it got %s", jsonText(value))
+		}
+
+		if operator == expect.OperatorGreaterThan {
+			return comparison > 0, nil
+		}
+		return comparison < 0, nil
+	case expect.OperatorRegex:
+		text, ok := value.(string)
+		if !ok {
+			text = jsonText(value)
+		}
+		return regexp.MustCompile(expected.(string)).MatchString(text), nil
+	case expect.OperatorContains:
+		switch value := value.(type) {
+		case string:
+			substring, ok := expected.(string)
+			return ok && strings.Contains(value, substring), nil
+		case []interface{}:
+			return containsValue(value, expected), nil
+		case map[string]interface{}:
+			key, ok := expected.(string)
+			_, found := value[key]
+			return ok && found, nil
+		}
+		return false, fmt.Errorf("should be a string, list or object to contain a value, it got %s", jsonText(value))
+	case expect.OperatorOneOf:
+		return containsValue(expected.([]interface{}), value), nil
+	}
+
+	return false, fmt.Errorf("has an unknown operator '%s'", operator)
+}
+
+// validateField returns why the expected field is invalid
+func validateField(field expect.Field) error {
+	if field.Path == "" {
+		return errors.New("path is required")
+	}
+
+	operator := fieldOperator(field)
+	if _, ok := operatorDescriptions[operator]; !ok {
+		return fmt.Errorf("operator '%s' is unknown", operator)
+	}
+
+	value, err := normalizeJSON(field.Value)
+	if err != nil {
+		return fmt.Errorf("value can't be marshaled to JSON: %w", err)
+	}
+
+	switch operator {
+	case expect.OperatorLength, expect.OperatorGreaterThan, expect.OperatorLessThan:
+		if _, ok := value.(json.Number); !ok {
+			return fmt.Errorf("value of operator %s should be a number", operator)
+		}
+	case expect.OperatorRegex:
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("value of operator regex should be a string")
+		}
+
+		_, err := regexp.Compile(text)
+		if err != nil {
+			return fmt.Errorf("failed to compile regex: %w", err)
+		}
+	case expect.OperatorOneOf:
+		if _, ok := value.([]interface{}); !ok {
+			return errors.New("value of operator oneOf should be a list")
+		}
+	}
+
+	return nil
+}
+
+func fieldOperator(field expect.Field) expect.Operator {
+	if field.Operator == "" {
+		return expect.OperatorEquals
+	}
+	return field.Operator
+}
+
+func fieldLength(value interface{}) (int, bool) {
+	switch value := value.(type) {
+	case []interface{}:
+		return len(value), true
+	case map[string]interface{}:
+		return len(value), true
+	case string:
+		return utf8.RuneCountInString(value), true
+	}
+	return 0, false
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if equalJSON(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON returns true if the values decoded from JSON are equal, where the numbers are compared by their value
+// eg: 1 and 1.0
+func equalJSON(a, b interface{}) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		comparison, ok := compareNumbers(a, b)
+		return ok && comparison == 0
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for i := range a {
+			if !equalJSON(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+
+		for key, value := range a {
+			other, found := b[key]
+			if !found || !equalJSON(value, other) {
+				return false
+			}
+		}
+		return true
+	case string, bool, nil:
+		return a == b
+	}
+	return false
+}
+
+// compareNumbers returns -1, 0 or 1 if a is less than, equal to or greater than b,
+// parsed with enough precision to keep 64-bit integers exact.
+// It returns false if any of them is not a number.
+func compareNumbers(a, b json.Number) (int, bool) {
+	x, _, err := big.ParseFloat(string(a), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return 0, false
+	}
+
+	y, _, err := big.ParseFloat(string(b), 10, 256, big.ToNearestEven)
+	if err != nil {
+		return 0, false
+	}
+
+	return x.Cmp(y), true
+}
+
+// normalizeJSON returns the value as it's decoded from JSON, so it can be compared with the fields of a document
+func normalizeJSON(value interface{}) (interface{}, error) {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeJSON(content)
+}
+
+// decodeJSON decodes a JSON keeping the numbers as json.Number, so large integers (eg: 64-bit IDs) don't lose precision
+func decodeJSON(content []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid character after top-level value")
+	}
+
+	return value, nil
+}
+
+// jsonText returns the value as JSON to be shown in the failures
+func jsonText(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
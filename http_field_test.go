@@ -0,0 +1,174 @@
+package integration
+
+import (
+	goHTTP "net/http"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+func itemsHandler(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{
+		"id": "a1b2",
+		"status": "active",
+		"total": 3,
+		"items": [
+			{ "id": 1, "price": 9.5, "tags": ["new", "sale"] },
+			{ "id": 2, "price": 20, "tags": [] },
+			{ "id": 3, "price": 0.5, "tags": ["sale"] }
+		],
+		"owner": { "name": "foo" }
+	}`))
+}
+
+func TestHTTPFields(t *testing.T) {
+	err := Test(&Scenario{
+		Description: "TestHTTPFields",
+		Vars:        map[string]string{"status": "active"},
+		Steps: []Step{
+			{Test: &HTTPTestCase{
+				Handler: goHTTP.HandlerFunc(itemsHandler),
+				Request: call.Request{URL: "/items"},
+				Response: expect.Response{
+					StatusCode: goHTTP.StatusOK,
+					Fields: []expect.Field{
+						{Path: "status", Value: "{{status}}"},
+						{Path: "$.items[1].price", Operator: expect.OperatorEquals, Value: 20},
+						{Path: "total", Operator: expect.OperatorNotEquals, Value: 4},
+						{Path: "owner.name", Operator: expect.OperatorExists},
+						{Path: "deleted_at", Operator: expect.OperatorAbsent},
+						{Path: "items", Operator: expect.OperatorLength, Value: 3},
+						{Path: "owner.name", Operator: expect.OperatorLength, Value: 3},
+						{Path: "items[0].price", Operator: expect.OperatorGreaterThan, Value: 0},
+						{Path: "items[2].price", Operator: expect.OperatorLessThan, Value: 1},
+						{Path: "id", Operator: expect.OperatorRegex, Value: "^[a-z0-9]{4}$"},
+						{Path: "items[0].tags", Operator: expect.OperatorContains, Value: "sale"},
+						{Path: "owner", Operator: expect.OperatorContains, Value: "name"},
+						{Path: "status", Operator: expect.OperatorContains, Value: "act"},
+						{Path: "status", Operator: expect.OperatorOneOf, Value: []string{"active", "inactive"}},
+						{Path: "items[0]", Value: map[string]interface{}{"id": 1, "price": 9.5, "tags": []string{"new", "sale"}}},
+					},
+				},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHTTPFields_Wrong(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHTTPFields_Wrong",
+		Handler:     goHTTP.HandlerFunc(itemsHandler),
+		Request:     call.Request{URL: "/items"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Fields: []expect.Field{
+				{Path: "status", Value: "inactive"},
+				{Path: "owner.email", Operator: expect.OperatorExists},
+				{Path: "owner", Operator: expect.OperatorAbsent},
+				{Path: "items", Operator: expect.OperatorLength, Value: 20},
+				{Path: "items[1].price", Operator: expect.OperatorLessThan, Value: 10},
+				{Path: "items[3].price", Operator: expect.OperatorGreaterThan, Value: 0},
+				{Path: "status", Operator: expect.OperatorOneOf, Value: []string{"deleted"}},
+				{Path: "owner", Operator: expect.OperatorGreaterThan, Value: 1},
+			},
+		},
+	})
+
+	expected := []string{
+		`response field 'status' should be equal to "inactive" it got "active"`,
+		"response field 'owner.email' should exist",
+		`response field 'owner' should be absent it got {"name":"foo"}`,
+		"response field 'items' should have length 20 it got 3",
+		"response field 'items[1].price' should be less than 10 it got 20",
+		"response field 'items[3].price' should be greater than 0 it is absent",
+		`response field 'status' should be one of ["deleted"] it got "active"`,
+		`response field 'owner' should be a number to be compared, it got {"name":"foo"}`,
+	}
+	if len(result.Failures) != len(expected) {
+		t.Fatalf("it should have %d failures, it got %v", len(expected), result.Err())
+	}
+	for _, message := range expected {
+		if !strings.Contains(result.Err().Error(), message) {
+			t.Fatalf("it should fail with '%s', it got %v", message, result.Err())
+		}
+	}
+}
+
+func TestHTTPFields_LargeNumbers(t *testing.T) {
+	// 9007199254740993 is 2^53 + 1, which a float64 rounds to 9007199254740992
+	handler := bodyHandler(`{"id": 9007199254740993, "ids": [9007199254740993], "price": 1.50}`)
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHTTPFields_LargeNumbers",
+		Handler:     handler,
+		Request:     call.Request{URL: "/items"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Fields: []expect.Field{
+				{Path: "id", Value: uint64(9007199254740993)},
+				{Path: "id", Operator: expect.OperatorNotEquals, Value: int64(9007199254740992)},
+				{Path: "id", Operator: expect.OperatorGreaterThan, Value: int64(9007199254740992)},
+				{Path: "id", Operator: expect.OperatorOneOf, Value: []int64{9007199254740993}},
+				{Path: "ids", Value: []int64{9007199254740993}},
+				{Path: "price", Value: 1.5},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHTTPFields_LargeNumbers wrong",
+		Handler:     handler,
+		Request:     call.Request{URL: "/items"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Fields: []expect.Field{
+				{Path: "id", Value: int64(9007199254740992)},
+				{Path: "id", Operator: expect.OperatorOneOf, Value: []int64{9007199254740992}},
+			},
+		},
+	})
+
+	expected := []string{
+		"response field 'id' should be equal to 9007199254740992 it got 9007199254740993",
+		"response field 'id' should be one of [9007199254740992] it got 9007199254740993",
+	}
+	if len(result.Failures) != len(expected) {
+		t.Fatalf("it should have %d failures, it got %v", len(expected), result.Err())
+	}
+	for _, message := range expected {
+		if !strings.Contains(result.Err().Error(), message) {
+			t.Fatalf("it should fail with '%s', it got %v", message, result.Err())
+		}
+	}
+}
+
+func TestHTTPFields_Invalid(t *testing.T) {
+	tests := map[string]expect.Field{
+		"no path":          {Value: 1},
+		"unknown operator": {Path: "id", Operator: "foo"},
+		"length string":    {Path: "id", Operator: expect.OperatorLength, Value: "1"},
+		"invalid regex":    {Path: "id", Operator: expect.OperatorRegex, Value: "("},
+		"oneOf not a list": {Path: "id", Operator: expect.OperatorOneOf, Value: "a"},
+	}
+
+	for name, field := range tests {
+		result := Evaluate(&HTTPTestCase{
+			Description: "TestHTTPFields_Invalid " + name,
+			Handler:     goHTTP.HandlerFunc(itemsHandler),
+			Request:     call.Request{URL: "/items"},
+			Response:    expect.Response{StatusCode: goHTTP.StatusOK, Fields: []expect.Field{field}},
+		})
+		if result.Passed() || result.Failures[0].Phase != PhaseValidate {
+			t.Fatalf("%s: it should fail validation, it got %v", name, result.Err())
+		}
+	}
+}
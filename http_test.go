@@ -1,17 +1,22 @@
 package integration
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
 	goHTTP "net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/lucasvmiguel/integration/assertion"
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/mock"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -421,6 +426,280 @@ func TestHandlerCallHTTPGet_InvalidSQL(t *testing.T) {
 	}
 }
 
+func TestHandlerCallHTTPGet_ParallelWithMock(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		i := i
+		t.Run(fmt.Sprintf("case %d", i), func(t *testing.T) {
+			t.Parallel()
+
+			transport := mock.NewTransport()
+			server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+				resp, err := transport.Client().Get(fmt.Sprintf("https://jsonplaceholder.typicode.com/posts/%d", i))
+				if err != nil {
+					goHTTP.Error(w, err.Error(), goHTTP.StatusInternalServerError)
+					return
+				}
+
+				w.WriteHeader(resp.StatusCode)
+			}))
+			defer server.Close()
+
+			err := Test(&HTTPTestCase{
+				Description: fmt.Sprintf("TestHandlerCallHTTPGet_ParallelWithMock_%d", i),
+				Request: call.Request{
+					URL: server.URL,
+				},
+				Response: expect.Response{
+					StatusCode: goHTTP.StatusAccepted,
+				},
+				Mock: transport,
+				Assertions: []assertion.Assertion{
+					&assertion.HTTP{
+						Request: expect.Request{
+							URL:    fmt.Sprintf("https://jsonplaceholder.typicode.com/posts/%d", i),
+							Method: goHTTP.MethodGet,
+						},
+						Response: mock.Response{
+							StatusCode: goHTTP.StatusAccepted,
+						},
+					},
+				},
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestHandlerCallHTTPPost_SuccessWithMockServer(t *testing.T) {
+	mockServer := mock.NewServer()
+	defer mockServer.Close()
+
+	// the service uses its own client and transport, so only a mock server is able to intercept its requests
+	client := &goHTTP.Client{Transport: &goHTTP.Transport{}}
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		resp, err := client.Post(mockServer.URL()+"/posts", "application/json", req.Body)
+		if err != nil {
+			goHTTP.Error(w, err.Error(), goHTTP.StatusInternalServerError)
+			return
+		}
+		defer resp.Body.Close()
+
+		w.WriteHeader(goHTTP.StatusCreated)
+		io.Copy(w, resp.Body)
+	}))
+	defer server.Close()
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPPost_SuccessWithMockServer",
+		Request: call.Request{
+			URL:    server.URL,
+			Method: goHTTP.MethodPost,
+			Body:   `{"title": "some title"}`,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusCreated,
+			Body:       `{"id": 1, "title": "some title"}`,
+		},
+		Mock: mockServer,
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "/posts",
+					Method: goHTTP.MethodPost,
+					Body:   `{"title": "some title"}`,
+				},
+				Response: mock.Response{
+					StatusCode: goHTTP.StatusCreated,
+					Body:       `{"id": 1, "title": "some title"}`,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlerCallHTTPPost_FailedMockServerBody(t *testing.T) {
+	mockServer := mock.NewServer()
+	defer mockServer.Close()
+
+	// the service ignores the response of the mock server, so only the assertion is able to detect the wrong body
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		resp, err := goHTTP.Post(mockServer.URL()+"/posts", "application/json", strings.NewReader(`{"title": "other title"}`))
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		w.WriteHeader(goHTTP.StatusCreated)
+	}))
+	defer server.Close()
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPPost_FailedMockServerBody",
+		Request: call.Request{
+			URL:    server.URL,
+			Method: goHTTP.MethodPost,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusCreated,
+		},
+		Mock: mockServer,
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "/posts",
+					Method: goHTTP.MethodPost,
+					Body:   `{"title": "some title"}`,
+				},
+				Response: mock.Response{StatusCode: goHTTP.StatusCreated},
+			},
+		},
+	})
+
+	if result.Passed() || result.Failures[0].Phase != PhaseAssertion {
+		t.Fatalf("it should fail due to the body of the mocked request, it got %v", result.Err())
+	}
+}
+
+func TestHandlerCallHTTPGet_Timeout(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		select {
+		case <-req.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPGet_Timeout",
+		Request: call.Request{
+			URL:     server.URL,
+			Timeout: 50 * time.Millisecond,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+		},
+	})
+
+	if len(result.Failures) != 1 || result.Failures[0].Phase != PhaseCall || result.Failures[0].Kind != KindTimeout {
+		t.Fatalf("it should fail due to timeout, it got %v", result.Err())
+	}
+}
+
+func TestHandlerCallHTTPGet_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := TestContext(ctx, &HTTPTestCase{
+		Description: "TestHandlerCallHTTPGet_ContextCanceled",
+		Request: call.Request{
+			URL: "http://localhost:8080/handlerCallHTTPGet",
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+		},
+	})
+
+	if !strings.Contains(fmt.Sprint(err), "context canceled") {
+		t.Fatalf("it should fail due to the context canceled, it got %v", err)
+	}
+}
+
+func TestHandlerCallHTTPPost_SuccessWithEventually(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		notifyURL := req.Header.Get("X-Notify-URL")
+
+		// notifies after responding
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			goHTTP.Post(notifyURL, "application/json", nil)
+		}()
+
+		w.WriteHeader(goHTTP.StatusAccepted)
+	}))
+	defer server.Close()
+
+	mockServer := mock.NewServer()
+	defer mockServer.Close()
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPPost_SuccessWithEventually",
+		Request: call.Request{
+			URL:    server.URL,
+			Method: goHTTP.MethodPost,
+			Header: goHTTP.Header{"X-Notify-Url": []string{mockServer.URL() + "/notifications"}},
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusAccepted,
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.Eventually{
+				Assertion: &assertion.HTTP{
+					Request:  expect.Request{URL: "/notifications", Method: goHTTP.MethodPost},
+					Response: mock.Response{StatusCode: goHTTP.StatusOK},
+				},
+				Timeout: time.Second,
+			},
+		},
+		Mock: mockServer,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlerCallHTTPPost_TimeoutWithEventually(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		notifyURL := req.Header.Get("X-Notify-URL")
+
+		// notifies after the request timeout
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			goHTTP.Post(notifyURL, "application/json", nil)
+		}()
+
+		w.WriteHeader(goHTTP.StatusAccepted)
+	}))
+	defer server.Close()
+
+	mockServer := mock.NewServer()
+	defer mockServer.Close()
+
+	// the timeout of the request doesn't limit the assertions
+	err := Test(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPPost_TimeoutWithEventually",
+		Request: call.Request{
+			URL:     server.URL,
+			Method:  goHTTP.MethodPost,
+			Header:  goHTTP.Header{"X-Notify-Url": []string{mockServer.URL() + "/notifications"}},
+			Timeout: 50 * time.Millisecond,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusAccepted,
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.Eventually{
+				Assertion: &assertion.HTTP{
+					Request:  expect.Request{URL: "/notifications", Method: goHTTP.MethodPost},
+					Response: mock.Response{StatusCode: goHTTP.StatusOK},
+				},
+				Timeout: time.Second,
+			},
+		},
+		Mock: mockServer,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func connectToDatabase() (*sql.DB, error) {
 	db, err := sql.Open("sqlite3", "./database.db")
 	if err != nil {
@@ -438,3 +717,102 @@ func seed(db *sql.DB) {
 	db.Exec("INSERT INTO products (title, description, category_id) VALUES ('foo1', 'bar1', 1);")
 	db.Exec("INSERT INTO products (title, description, category_id) VALUES ('foo2', 'bar2', 1);")
 }
+
+func TestHandlerCallHTTPPostJSON_InMemoryHandler(t *testing.T) {
+	handler := goHTTP.NewServeMux()
+	handler.HandleFunc("/handlerCallHTTPPostJSON", handlerCallHTTPPostJSON)
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPPostJSON_InMemoryHandler",
+		Handler:     handler,
+		Request: call.Request{
+			URL:    "/handlerCallHTTPPostJSON",
+			Method: goHTTP.MethodPost,
+			Body: `{
+				"title": "some title",
+				"userId": 1
+			}`,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusCreated,
+			Body: `{
+				"title": "some title",
+				"description": "<<PRESENCE>>",
+				"userId": 1,
+				"comments": "<<PRESENCE>>"
+			}`,
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{
+				Request: expect.Request{
+					URL:    "https://jsonplaceholder.typicode.com/posts",
+					Method: goHTTP.MethodPost,
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlerCallHTTPGet_InMemoryHandler(t *testing.T) {
+	handler := goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		if req.URL.Path == "/panic" {
+			panic("failed")
+		}
+
+		w.Header().Set("X-Request-URI", req.RequestURI)
+		w.WriteHeader(goHTTP.StatusAccepted)
+	})
+
+	err := Test(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPGet_InMemoryHandler",
+		Handler:     handler,
+		Request: call.Request{
+			URL: "https://example.com/posts?id=1",
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusAccepted,
+			Header:     goHTTP.Header{"X-Request-Uri": []string{"/posts?id=1"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPGet_InMemoryHandler",
+		Handler:     handler,
+		Request: call.Request{
+			URL: "/panic",
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+		},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "handler panicked: failed") {
+		t.Fatalf("it should fail due to the handler panic, it got %v", result.Err())
+	}
+}
+
+func TestHandlerCallHTTPGet_InMemoryHandlerTimeout(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestHandlerCallHTTPGet_InMemoryHandlerTimeout",
+		Handler: goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+			time.Sleep(time.Second)
+		}),
+		Request: call.Request{
+			URL:     "/",
+			Timeout: 50 * time.Millisecond,
+		},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+		},
+	})
+
+	if result.Passed() || result.Failures[0].Kind != KindTimeout || result.Duration >= time.Second {
+		t.Fatalf("it should fail due to timeout, it got %v", result.Err())
+	}
+}
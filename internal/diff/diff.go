@@ -0,0 +1,218 @@
+// Package diff describes how an actual value differs from the expected one as a unified diff
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// header is the first lines of a diff, naming its sides
+const header = "--- expected\n+++ actual\n"
+
+// context is the number of unchanged lines around the changes of a text diff
+const context = 3
+
+// maxCells is the size limit of the table used to find the common lines of two texts,
+// larger texts have their different lines shown as removed and added
+const maxCells = 1 << 22
+
+// ANSI colors of the lines of a diff
+const (
+	reset = "\x1b[0m"
+	bold  = "\x1b[1m"
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	cyan  = "\x1b[36m"
+)
+
+// line is a line of a text diff
+type line struct {
+	// op is ' ' if the line is in both texts, '-' if it's only in the expected one and '+' if it's only in the actual one
+	op   byte
+	text string
+}
+
+// Text returns the differences of the actual text from the expected one, line by line,
+// with 3 lines of context around each hunk
+// eg:
+//
+//	--- expected
+//	+++ actual
+//	@@ -1,2 +1,2 @@
+//	 first
+//	-second
+//	+third
+//
+// It returns an empty string if the texts are equal.
+func Text(expected, actual string) string {
+	if expected == actual {
+		return ""
+	}
+
+	lines := compare(strings.Split(expected, "\n"), strings.Split(actual, "\n"))
+
+	builder := strings.Builder{}
+	builder.WriteString(header)
+
+	// position of the current line in each text, starting at 1
+	expectedLine, actualLine := 1, 1
+	for start := 0; start < len(lines); {
+		if lines[start].op == ' ' {
+			start++
+			expectedLine++
+			actualLine++
+			continue
+		}
+
+		// the hunk starts before the change and goes on while the changes are at most twice the context apart
+		first := start - context
+		if first < 0 {
+			first = 0
+		}
+		last := start
+		for i := start; i < len(lines) && i-last <= 2*context+1; i++ {
+			if lines[i].op != ' ' {
+				last = i
+			}
+		}
+		end := last + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		expectedStart, actualStart := expectedLine-(start-first), actualLine-(start-first)
+		expectedCount, actualCount := 0, 0
+		for _, l := range lines[first:end] {
+			if l.op != '+' {
+				expectedCount++
+			}
+			if l.op != '-' {
+				actualCount++
+			}
+		}
+
+		fmt.Fprintf(&builder, "@@ -%s +%s @@\n", hunkRange(expectedStart, expectedCount), hunkRange(actualStart, actualCount))
+		for _, l := range lines[first:end] {
+			builder.WriteByte(l.op)
+			builder.WriteString(l.text)
+			builder.WriteByte('\n')
+		}
+
+		for _, l := range lines[start:end] {
+			if l.op != '+' {
+				expectedLine++
+			}
+			if l.op != '-' {
+				actualLine++
+			}
+		}
+		start = end
+	}
+
+	return builder.String()
+}
+
+// hunkRange returns the start and the number of lines of a hunk in a text
+// eg: 3,4
+func hunkRange(start, count int) string {
+	if count == 0 {
+		// an empty range starts at the line before it, as in diff -u
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// compare returns the lines of both texts, keeping the longest sequence of lines they have in common
+func compare(expected, actual []string) []line {
+	prefix := 0
+	for prefix < len(expected) && prefix < len(actual) && expected[prefix] == actual[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(expected)-prefix && suffix < len(actual)-prefix &&
+		expected[len(expected)-1-suffix] == actual[len(actual)-1-suffix] {
+		suffix++
+	}
+
+	lines := []line{}
+	for _, text := range expected[:prefix] {
+		lines = append(lines, line{op: ' ', text: text})
+	}
+	lines = append(lines, common(expected[prefix:len(expected)-suffix], actual[prefix:len(actual)-suffix])...)
+	for _, text := range expected[len(expected)-suffix:] {
+		lines = append(lines, line{op: ' ', text: text})
+	}
+
+	return lines
+}
+
+// common returns the lines of both texts using the table of their longest common subsequences
+func common(expected, actual []string) []line {
+	lines := []line{}
+
+	if (len(expected)+1)*(len(actual)+1) > maxCells {
+		for _, text := range expected {
+			lines = append(lines, line{op: '-', text: text})
+		}
+		for _, text := range actual {
+			lines = append(lines, line{op: '+', text: text})
+		}
+		return lines
+	}
+
+	// lengths[i][j] is the length of the longest common subsequence of expected[i:] and actual[j:]
+	lengths := make([][]int, len(expected)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(actual)+1)
+	}
+	for i := len(expected) - 1; i >= 0; i-- {
+		for j := len(actual) - 1; j >= 0; j-- {
+			switch {
+			case expected[i] == actual[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(expected) || j < len(actual) {
+		switch {
+		case i < len(expected) && j < len(actual) && expected[i] == actual[j]:
+			lines = append(lines, line{op: ' ', text: expected[i]})
+			i++
+			j++
+		case j == len(actual) || i < len(expected) && lengths[i+1][j] >= lengths[i][j+1]:
+			lines = append(lines, line{op: '-', text: expected[i]})
+			i++
+		default:
+			lines = append(lines, line{op: '+', text: actual[j]})
+			j++
+		}
+	}
+
+	return lines
+}
+
+// Color returns the diff with ANSI colors: the removed lines in red, the added ones in green
+// and the hunk headers in cyan
+func Color(diff string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+	for i, l := range lines {
+		switch {
+		case i < 2 && (strings.HasPrefix(l, "--- ") || strings.HasPrefix(l, "+++ ")):
+			lines[i] = bold + l + reset
+		case strings.HasPrefix(l, "@@"):
+			lines[i] = cyan + l + reset
+		case strings.HasPrefix(l, "-"):
+			lines[i] = red + l + reset
+		case strings.HasPrefix(l, "+"):
+			lines[i] = green + l + reset
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
@@ -0,0 +1,206 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestText(t *testing.T) {
+	expected := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm"
+	actual := "a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nm\nn"
+
+	result := Text(expected, actual)
+
+	want := `--- expected
++++ actual
+@@ -1,5 +1,5 @@
+ a
+-b
++B
+ c
+ d
+ e
+@@ -9,5 +9,5 @@
+ i
+ j
+ k
+-l
+ m
++n
+`
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestText_MergesCloseChanges(t *testing.T) {
+	result := Text("a\nb\nc\nd\ne\nf\ng\nh\ni", "a\nB\nc\nd\ne\nf\ng\nH\ni")
+
+	want := `--- expected
++++ actual
+@@ -1,9 +1,9 @@
+ a
+-b
++B
+ c
+ d
+ e
+ f
+ g
+-h
++H
+ i
+`
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestText_Added(t *testing.T) {
+	result := Text("a", "a\nb")
+
+	want := "--- expected\n+++ actual\n@@ -1,1 +1,2 @@\n a\n+b\n"
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+
+	result = Text("", "a")
+
+	want = "--- expected\n+++ actual\n@@ -1,1 +1,1 @@\n-\n+a\n"
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestText_Equal(t *testing.T) {
+	result := Text("a\nb", "a\nb")
+	if result != "" {
+		t.Fatalf("diff should be empty, it got:\n%s", result)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	expected := `{
+		"id": 1,
+		"title": "foo",
+		"tags": ["a", "b", "c"],
+		"author": {"name": "bar", "email": "bar@example.com"},
+		"dotted.key": true,
+		"missing": null
+	}`
+	actual := `{
+		"id": 1.0,
+		"title": "baz",
+		"tags": ["a", "b"],
+		"author": {"name": "bar", "email": "qux@example.com", "age": 30},
+		"dotted.key": false
+	}`
+
+	result := JSON(expected, actual)
+
+	want := `--- expected
++++ actual
+@@ $.author.age @@
++30
+@@ $.author.email @@
+-"bar@example.com"
++"qux@example.com"
+@@ $['dotted.key'] @@
+-true
++false
+@@ $.missing @@
+-null
+@@ $.tags[2] @@
+-"c"
+@@ $.title @@
+-"foo"
++"baz"
+`
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestJSON_Placeholders(t *testing.T) {
+	expected := `{
+		"id": "<<UUID>>",
+		"count": "<<RANGE:1,10>>",
+		"createdAt": "<<PRESENCE>>",
+		"tags": ["<<UNORDERED>>", "a", "b"],
+		"items": ["<<UNORDERED>>", 1, 2]
+	}`
+	actual := `{
+		"id": "5f1c3a52-7b7e-4c1b-9d2e-3f5a6b7c8d9e",
+		"count": 20,
+		"createdAt": "2020-01-01T00:00:00Z",
+		"tags": ["b", "a"],
+		"items": [2, 3]
+	}`
+
+	result := JSON(expected, actual)
+
+	want := `--- expected
++++ actual
+@@ $.count @@
+-"<<RANGE:1,10>>"
++20
+@@ $.items @@
+-["<<UNORDERED>>",1,2]
++[2,3]
+`
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestJSON_Types(t *testing.T) {
+	result := JSON(`{"items": [1]}`, `{"items": {"id": 1}}`)
+
+	want := "--- expected\n+++ actual\n@@ $.items @@\n-[1]\n+{\"id\":1}\n"
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestJSON_LargeNumbers(t *testing.T) {
+	result := JSON(`{"id": 1234567890123456789}`, `{"id": 1234567890123456788}`)
+
+	want := "--- expected\n+++ actual\n@@ $.id @@\n-1234567890123456789\n+1234567890123456788\n"
+	if result != want {
+		t.Fatalf("diff should be:\n%s\nit got:\n%s", want, result)
+	}
+}
+
+func TestJSON_EqualOrInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+	}{
+		{name: "equal", expected: `{"id": 1, "tags": ["a"]}`, actual: `{"tags": ["a"], "id": 1}`},
+		{name: "invalid expected", expected: `{"id": 1`, actual: `{"id": 2}`},
+		{name: "invalid actual", expected: `{"id": 1}`, actual: `not found`},
+	}
+
+	for _, tt := range tests {
+		result := JSON(tt.expected, tt.actual)
+		if result != "" {
+			t.Fatalf("%s: diff should be empty, it got:\n%s", tt.name, result)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	result := Color("--- expected\n+++ actual\n@@ $.title @@\n-\"foo\"\n+\"bar\"\n")
+
+	want := strings.Join([]string{
+		bold + "--- expected" + reset,
+		bold + "+++ actual" + reset,
+		cyan + "@@ $.title @@" + reset,
+		red + `-"foo"` + reset,
+		green + `+"bar"` + reset,
+	}, "\n") + "\n"
+	if result != want {
+		t.Fatalf("diff should be %q, it got %q", want, result)
+	}
+}
@@ -0,0 +1,237 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lucasvmiguel/integration/matcher"
+)
+
+// keyPattern matches the object keys written after a dot in a path, the others are quoted
+// eg: $.title and $['dotted.key']
+var keyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// change is a value that differs at a path, where a missing value is empty
+type change struct {
+	path     string
+	expected string
+	actual   string
+}
+
+// JSON returns the differences of the actual JSON from the expected one, with a hunk for each path that differs
+// eg:
+//
+//	--- expected
+//	+++ actual
+//	@@ $.title @@
+//	-"foo"
+//	+"bar"
+//	@@ $.tags[2] @@
+//	-"b"
+//
+// The placeholders of the expected JSON (eg: "<<UUID>>") are equal to the values that match them,
+// and the lists with <<UNORDERED>> are equal to the lists with the same items in any order.
+// It returns an empty string if the JSONs are equal or any of them is invalid.
+func JSON(expected, actual string) string {
+	expectedValue, err := decode(expected)
+	if err != nil {
+		return ""
+	}
+
+	actualValue, err := decode(actual)
+	if err != nil {
+		return ""
+	}
+
+	changes := []change{}
+	walk("$", expectedValue, actualValue, &changes)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(header)
+	for _, c := range changes {
+		fmt.Fprintf(&builder, "@@ %s @@\n", c.path)
+		if c.expected != "" {
+			fmt.Fprintf(&builder, "-%s\n", c.expected)
+		}
+		if c.actual != "" {
+			fmt.Fprintf(&builder, "+%s\n", c.actual)
+		}
+	}
+
+	return builder.String()
+}
+
+// walk adds the changes of the actual value from the expected one at the path
+func walk(path string, expected, actual interface{}, changes *[]change) {
+	switch e := expected.(type) {
+	case string:
+		if e == matcher.Presence {
+			return
+		}
+		if matcher.IsPlaceholder(e) {
+			if matcher.Match(actual, e) != nil {
+				*changes = append(*changes, change{path: path, expected: encode(e), actual: encode(actual)})
+			}
+			return
+		}
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		for _, key := range keys(e, a) {
+			keyPath := childPath(path, key)
+			expectedValue, expectedOK := e[key]
+			actualValue, actualOK := a[key]
+
+			switch {
+			case !actualOK:
+				*changes = append(*changes, change{path: keyPath, expected: encode(expectedValue)})
+			case !expectedOK:
+				*changes = append(*changes, change{path: keyPath, actual: encode(actualValue)})
+			default:
+				walk(keyPath, expectedValue, actualValue, changes)
+			}
+		}
+		return
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			break
+		}
+
+		if len(e) > 0 && e[0] == matcher.Unordered {
+			if !sameItems(e[1:], a) {
+				*changes = append(*changes, change{path: path, expected: encode(e), actual: encode(a)})
+			}
+			return
+		}
+
+		for i := 0; i < len(e) || i < len(a); i++ {
+			indexPath := fmt.Sprintf("%s[%d]", path, i)
+
+			switch {
+			case i >= len(a):
+				*changes = append(*changes, change{path: indexPath, expected: encode(e[i])})
+			case i >= len(e):
+				*changes = append(*changes, change{path: indexPath, actual: encode(a[i])})
+			default:
+				walk(indexPath, e[i], a[i], changes)
+			}
+		}
+		return
+	}
+
+	if !equal(expected, actual) {
+		*changes = append(*changes, change{path: path, expected: encode(expected), actual: encode(actual)})
+	}
+}
+
+// sameItems returns true if each expected item is equal to a different actual item
+func sameItems(expected, actual []interface{}) bool {
+	if len(expected) != len(actual) {
+		return false
+	}
+
+	used := make([]bool, len(actual))
+	for _, e := range expected {
+		found := false
+		for i, a := range actual {
+			if used[i] {
+				continue
+			}
+
+			changes := []change{}
+			walk("$", e, a, &changes)
+			if len(changes) == 0 {
+				used[i] = true
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// equal returns true if the scalar values are equal, where the numbers are compared by their exact value
+// eg: 1 and 1.0
+func equal(expected, actual interface{}) bool {
+	switch e := expected.(type) {
+	case json.Number:
+		a, ok := actual.(json.Number)
+		if !ok {
+			return false
+		}
+
+		// the precision keeps 64-bit integers exact, which float64 doesn't
+		expectedFloat, _, expectedErr := big.ParseFloat(string(e), 10, 256, big.ToNearestEven)
+		actualFloat, _, actualErr := big.ParseFloat(string(a), 10, 256, big.ToNearestEven)
+		if expectedErr != nil || actualErr != nil {
+			return e == a
+		}
+		return expectedFloat.Cmp(actualFloat) == 0
+	case string, bool, nil:
+		return expected == actual
+	}
+	return false
+}
+
+// keys returns the keys of both objects sorted
+func keys(expected, actual map[string]interface{}) []string {
+	keys := []string{}
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	for key := range actual {
+		if _, ok := expected[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// childPath returns the path of a key of the object at the path
+// eg: $.title or $['dotted.key']
+func childPath(path, key string) string {
+	if keyPattern.MatchString(key) {
+		return path + "." + key
+	}
+	return fmt.Sprintf("%s['%s']", path, strings.ReplaceAll(key, "'", `\'`))
+}
+
+// encode returns the value as a compact JSON
+func encode(value interface{}) string {
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// decode decodes a JSON keeping the numbers as json.Number
+func decode(content string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
@@ -0,0 +1,99 @@
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when a path doesn't exist in a JSON document
+var ErrNotFound = errors.New("path not found")
+
+// segment is a part of a path, either an object key or an array index
+type segment struct {
+	key   string
+	index int
+	isKey bool
+}
+
+// Get returns the value of a path in a JSON document decoded by encoding/json.
+// A path is a list of keys separated by dots, where array indexes can be used with brackets or as a key.
+// The root `$` is optional.
+// eg: $.items[0].id, items.0.id or items[0]['id']
+func Get(document interface{}, path string) (interface{}, error) {
+	segments, err := parse(path)
+	if err != nil {
+		return nil, err
+	}
+
+	value := document
+	for _, s := range segments {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			child, ok := v[s.key]
+			if !ok {
+				return nil, fmt.Errorf("%w: '%s' does not have the key '%s'", ErrNotFound, path, s.key)
+			}
+			value = child
+		case []interface{}:
+			index := s.index
+			if s.isKey {
+				index, err = strconv.Atoi(s.key)
+				if err != nil {
+					return nil, fmt.Errorf("%w: '%s' has an array where the key '%s' is", ErrNotFound, path, s.key)
+				}
+			}
+			if index < 0 || index >= len(v) {
+				return nil, fmt.Errorf("%w: '%s' does not have the index %d", ErrNotFound, path, index)
+			}
+			value = v[index]
+		default:
+			return nil, fmt.Errorf("%w: '%s' goes through a value that is not an object or array", ErrNotFound, path)
+		}
+	}
+
+	return value, nil
+}
+
+func parse(path string) ([]segment, error) {
+	rest := strings.TrimSpace(path)
+	rest = strings.TrimPrefix(rest, "$")
+	segments := []segment{}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("invalid path '%s': missing ']'", path)
+			}
+
+			content := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(content) >= 2 && (content[0] == '\'' || content[0] == '"') && content[len(content)-1] == content[0] {
+				segments = append(segments, segment{key: content[1 : len(content)-1], isKey: true})
+				continue
+			}
+
+			index, err := strconv.Atoi(content)
+			if err != nil {
+				return nil, fmt.Errorf("invalid path '%s': '%s' is not an array index", path, content)
+			}
+			segments = append(segments, segment{index: index})
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+
+			segments = append(segments, segment{key: rest[:end], isKey: true})
+			rest = rest[end:]
+		}
+	}
+
+	return segments, nil
+}
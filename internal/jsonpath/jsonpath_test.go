@@ -0,0 +1,93 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func document(t *testing.T) interface{} {
+	var v interface{}
+	err := json.Unmarshal([]byte(`{
+		"id": 1,
+		"title": "foo",
+		"items": [
+			{ "id": 10, "tags": ["a", "b"] },
+			{ "id": 20, "tags": [] }
+		],
+		"dotted.key": true
+	}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestGet(t *testing.T) {
+	doc := document(t)
+
+	tests := map[string]interface{}{
+		"id":                float64(1),
+		"$.title":           "foo",
+		"items[1].id":       float64(20),
+		"$.items.0.id":      float64(10),
+		"items[0].tags[1]":  "b",
+		"items[0]['id']":    float64(10),
+		"['dotted.key']":    true,
+		"$['items'][1].id":  float64(20),
+		"items.1.tags.0.id": nil,
+	}
+
+	for path, expected := range tests {
+		result, err := Get(doc, path)
+		if expected == nil {
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("path '%s' should not be found, it got '%v'", path, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("path '%s' failed: %v", path, err)
+		}
+
+		if result != expected {
+			t.Fatalf("path '%s' should be '%v', it got '%v'", path, expected, result)
+		}
+	}
+}
+
+func TestGet_Root(t *testing.T) {
+	doc := document(t)
+
+	result, err := Get(doc, "$")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := result.(map[string]interface{}); !ok {
+		t.Fatalf("root should be the whole document, it got '%v'", result)
+	}
+}
+
+func TestGet_NotFound(t *testing.T) {
+	doc := document(t)
+
+	for _, path := range []string{"unknown", "items[5]", "items.foo", "title.foo"} {
+		_, err := Get(doc, path)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("path '%s' should not be found, it got '%v'", path, err)
+		}
+	}
+}
+
+func TestGet_InvalidPath(t *testing.T) {
+	doc := document(t)
+
+	for _, path := range []string{"items[0", "items[foo]"} {
+		_, err := Get(doc, path)
+		if err == nil || errors.Is(err, ErrNotFound) {
+			t.Fatalf("path '%s' should be invalid, it got '%v'", path, err)
+		}
+	}
+}
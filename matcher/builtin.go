@@ -0,0 +1,190 @@
+package matcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// uuidPattern matches a UUID of any version, in lower or upper case
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// matchUUID matches a UUID
+// eg: <<UUID>>
+func matchUUID(value interface{}) error {
+	text, ok := value.(string)
+	if !ok || !uuidPattern.MatchString(text) {
+		return fmt.Errorf("%s is not a UUID", describe(value))
+	}
+	return nil
+}
+
+// matchRFC3339 matches a RFC 3339 timestamp, with or without fractional seconds
+// eg: <<RFC3339>>
+func matchRFC3339(value interface{}) error {
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%s is not a RFC 3339 timestamp", describe(value))
+	}
+
+	_, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return fmt.Errorf("%s is not a RFC 3339 timestamp", describe(value))
+	}
+	return nil
+}
+
+// matchNumber matches a number, or a string with a number (eg: an int64 field of a GRPC message)
+// eg: <<ANY_NUMBER>>
+func matchNumber(value interface{}) error {
+	_, err := number(value)
+	return err
+}
+
+// newRegex creates a matcher of a text, number or bool with the regular expression
+// eg: <<REGEX:^[a-z]+$>>
+func newRegex(args string) (Matcher, error) {
+	regex, err := regexp.Compile(args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile regex: %w", err)
+	}
+
+	return Func(func(value interface{}) error {
+		var text string
+		switch v := value.(type) {
+		case string:
+			text = v
+		case json.Number:
+			text = v.String()
+		case bool:
+			text = strconv.FormatBool(v)
+		default:
+			return fmt.Errorf("%s is not a text", describe(value))
+		}
+
+		if !regex.MatchString(text) {
+			return fmt.Errorf("%s does not match '%s'", describe(value), args)
+		}
+		return nil
+	}), nil
+}
+
+// newRange creates a matcher of a number between the min and the max, inclusive
+// eg: <<RANGE:1,10>>
+func newRange(args string) (Matcher, error) {
+	low, high, err := numberArgs(args, "min,max")
+	if err != nil {
+		return nil, err
+	}
+
+	if low > high {
+		return nil, fmt.Errorf("min %v is greater than max %v", low, high)
+	}
+
+	return Func(func(value interface{}) error {
+		n, err := number(value)
+		if err != nil {
+			return err
+		}
+
+		if n < low || n > high {
+			return fmt.Errorf("%s is not between %v and %v", describe(value), low, high)
+		}
+		return nil
+	}), nil
+}
+
+// newApprox creates a matcher of a number equal to the value, give or take the tolerance
+// eg: <<APPROX:3.14,0.01>>
+func newApprox(args string) (Matcher, error) {
+	expected, tolerance, err := numberArgs(args, "value,tolerance")
+	if err != nil {
+		return nil, err
+	}
+
+	if tolerance < 0 {
+		return nil, fmt.Errorf("tolerance %v is negative", tolerance)
+	}
+
+	return Func(func(value interface{}) error {
+		n, err := number(value)
+		if err != nil {
+			return err
+		}
+
+		if math.Abs(n-expected) > tolerance {
+			return fmt.Errorf("%s is not within %v of %v", describe(value), tolerance, expected)
+		}
+		return nil
+	}), nil
+}
+
+// numberArgs returns the two numbers of the arguments, described by format (eg: min,max)
+func numberArgs(args, format string) (float64, float64, error) {
+	first, second, ok := strings.Cut(args, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("arguments should be %s", format)
+	}
+
+	a, err := strconv.ParseFloat(strings.TrimSpace(first), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("arguments should be %s: %w", format, err)
+	}
+
+	b, err := strconv.ParseFloat(strings.TrimSpace(second), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("arguments should be %s: %w", format, err)
+	}
+
+	return a, b, nil
+}
+
+// number returns the value as a number, if it's a number or a string with a number
+func number(value interface{}) (float64, error) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	default:
+		return 0, fmt.Errorf("%s is not a number", describe(value))
+	}
+
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0, fmt.Errorf("%s is not a number", describe(value))
+	}
+	return n, nil
+}
+
+// normalize converts the value of a SQL result to a JSON value
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return json.Number(fmt.Sprint(v))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return value
+}
+
+// describe returns the value as it's written in a JSON
+// eg: "foo"
+func describe(value interface{}) string {
+	content, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(content)
+}
@@ -0,0 +1,121 @@
+package matcher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kinbiko/jsonassert"
+	"github.com/lucasvmiguel/integration/internal/utils"
+)
+
+// JSON returns why the actual JSON doesn't match the expected one, or nil if it does.
+// The values of the expected JSON can be placeholders (eg: "<<UUID>>"), besides <<PRESENCE>> and <<UNORDERED>>.
+// The placeholders in lists with <<UNORDERED>> are compared as text.
+func JSON(actual, expected string) error {
+	expected, errs := replace(actual, expected)
+
+	je := utils.JsonError{}
+	jsonassert.New(&je).Assertf(actual, expected)
+	if je.Err != nil {
+		errs = append(errs, je.Err.Error())
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(errs, "; "))
+}
+
+// replace matches the placeholders of the expected JSON with the values at the same path of the actual one,
+// and returns the expected JSON with the placeholders replaced by <<PRESENCE>>, so jsonassert compares the rest,
+// and the values that don't match.
+func replace(actual, expected string) (string, []string) {
+	expectedValue, err := decode(expected)
+	if err != nil {
+		return expected, nil
+	}
+
+	// an invalid actual JSON is reported by jsonassert
+	actualValue, _ := decode(actual)
+
+	m := &jsonMatch{}
+	expectedValue = m.walk("$", expectedValue, actualValue, true)
+	if !m.found {
+		return expected, nil
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(expectedValue)
+	if err != nil {
+		return expected, nil
+	}
+
+	return buffer.String(), m.errs
+}
+
+// jsonMatch walks the expected JSON matching its placeholders
+type jsonMatch struct {
+	// found is true if the expected JSON has any placeholder
+	found bool
+	// errs are the values that don't match their placeholders
+	errs []string
+}
+
+// walk returns the expected value with its placeholders replaced, matching them with the actual value at the path.
+// exists is false if the actual value is not at the path.
+func (m *jsonMatch) walk(path string, expected, actual interface{}, exists bool) interface{} {
+	switch e := expected.(type) {
+	case string:
+		if !IsPlaceholder(e) {
+			return e
+		}
+
+		m.found = true
+		if exists {
+			err := Match(actual, e)
+			if err != nil {
+				m.errs = append(m.errs, fmt.Sprintf("value at '%s' does not match %s: %v", path, e, err))
+			}
+		}
+		// a missing value is reported by jsonassert
+		return Presence
+	case map[string]interface{}:
+		a, _ := actual.(map[string]interface{})
+		for key, value := range e {
+			v, ok := a[key]
+			e[key] = m.walk(path+"."+key, value, v, exists && ok)
+		}
+		return e
+	case []interface{}:
+		if len(e) > 0 && e[0] == Unordered {
+			return e
+		}
+
+		a, _ := actual.([]interface{})
+		for i, value := range e {
+			var v interface{}
+			ok := i < len(a)
+			if ok {
+				v = a[i]
+			}
+			e[i] = m.walk(fmt.Sprintf("%s[%d]", path, i), value, v, exists && ok)
+		}
+		return e
+	}
+	return expected
+}
+
+// decode decodes a JSON keeping the numbers as json.Number
+func decode(content string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	var value interface{}
+	err := decoder.Decode(&value)
+	return value, err
+}
@@ -0,0 +1,121 @@
+// Package matcher matches values with placeholders (eg: <<UUID>>) in the expected bodies, messages and results,
+// besides <<PRESENCE>> and <<UNORDERED>>.
+package matcher
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Matcher matches a value with a placeholder.
+// The value is a string, json.Number, bool, nil, []interface{} or map[string]interface{}, as in a JSON.
+// The values of SQL results are converted (eg: []byte to string, int64 to json.Number and time.Time to RFC 3339).
+type Matcher interface {
+	// Match returns why the value doesn't match, or nil if it does
+	Match(value interface{}) error
+}
+
+// Func is a function used as a Matcher
+type Func func(value interface{}) error
+
+// Match calls the function
+func (f Func) Match(value interface{}) error {
+	return f(value)
+}
+
+// Factory creates the matcher of a placeholder with its arguments
+// eg: 1,10 for <<RANGE:1,10>>
+type Factory func(args string) (Matcher, error)
+
+// placeholderPattern matches a placeholder with its name and arguments
+// eg: <<RANGE:1,10>>
+var placeholderPattern = regexp.MustCompile(`(?s)^<<([A-Z][A-Z0-9_]*)(?::(.*))?>>$`)
+
+// namePattern matches the names of the placeholders
+var namePattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+// Presence is the placeholder of jsonassert that matches any value
+const Presence = "<<PRESENCE>>"
+
+// Unordered is the placeholder of jsonassert, as the first item of a list, that matches the items in any order
+const Unordered = "<<UNORDERED>>"
+
+// reserved are the names of the placeholders of jsonassert, which can't be registered
+var reserved = map[string]bool{"PRESENCE": true, "UNORDERED": true}
+
+var (
+	mutex     sync.RWMutex
+	factories = map[string]Factory{}
+)
+
+func init() {
+	Register("UUID", Func(matchUUID))
+	Register("RFC3339", Func(matchRFC3339))
+	Register("ANY_NUMBER", Func(matchNumber))
+	RegisterFactory("REGEX", newRegex)
+	RegisterFactory("RANGE", newRange)
+	RegisterFactory("APPROX", newApprox)
+}
+
+// Register registers a matcher of a placeholder without arguments.
+// The name is in upper case (eg: SLUG for <<SLUG>>), registering it again replaces the matcher.
+// It panics if the name is invalid or it's PRESENCE or UNORDERED.
+func Register(name string, matcher Matcher) {
+	RegisterFactory(name, func(args string) (Matcher, error) {
+		if args != "" {
+			return nil, fmt.Errorf("<<%s>> has no arguments", name)
+		}
+		return matcher, nil
+	})
+}
+
+// RegisterFactory registers the factory of a placeholder with arguments, written after a colon
+// (eg: PREFIX for <<PREFIX:user_>>), registering it again replaces the factory.
+// It panics if the name is invalid or it's PRESENCE or UNORDERED.
+func RegisterFactory(name string, factory Factory) {
+	if !namePattern.MatchString(name) || reserved[name] {
+		panic(fmt.Sprintf("matcher: invalid placeholder name '%s'", name))
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	factories[name] = factory
+}
+
+// IsPlaceholder returns true if the text is the placeholder of a registered matcher
+// eg: <<UUID>>
+func IsPlaceholder(text string) bool {
+	_, ok := factory(text)
+	return ok
+}
+
+// Match returns why the value doesn't match the placeholder, or nil if it does
+func Match(value interface{}, placeholder string) error {
+	f, ok := factory(placeholder)
+	if !ok {
+		return fmt.Errorf("%s is not a registered placeholder", placeholder)
+	}
+
+	matcher, err := f(placeholderPattern.FindStringSubmatch(placeholder)[2])
+	if err != nil {
+		return fmt.Errorf("invalid placeholder %s: %w", placeholder, err)
+	}
+
+	return matcher.Match(normalize(value))
+}
+
+// factory returns the factory of the placeholder, if it's registered
+func factory(placeholder string) (Factory, bool) {
+	submatches := placeholderPattern.FindStringSubmatch(placeholder)
+	if submatches == nil {
+		return nil, false
+	}
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	f, ok := factories[submatches[1]]
+	return f, ok
+}
@@ -0,0 +1,175 @@
+package matcher
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		placeholder string
+		value       interface{}
+		matches     bool
+	}{
+		{"<<UUID>>", "0c3a3a55-91b2-4a35-9a0e-5c0e7a3c3d6f", true},
+		{"<<UUID>>", "0C3A3A55-91B2-4A35-9A0E-5C0E7A3C3D6F", true},
+		{"<<UUID>>", "0c3a3a55", false},
+		{"<<UUID>>", json.Number("1"), false},
+		{"<<RFC3339>>", "2023-08-01T10:00:00Z", true},
+		{"<<RFC3339>>", "2023-08-01T10:00:00.123+02:00", true},
+		{"<<RFC3339>>", "2023-08-01", false},
+		{"<<RFC3339>>", time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC), true},
+		{"<<ANY_NUMBER>>", json.Number("3.14"), true},
+		{"<<ANY_NUMBER>>", "42", true},
+		{"<<ANY_NUMBER>>", int64(42), true},
+		{"<<ANY_NUMBER>>", "foo", false},
+		{"<<ANY_NUMBER>>", true, false},
+		{"<<REGEX:^[a-z]+$>>", "foo", true},
+		{"<<REGEX:^[a-z]+$>>", "Foo", false},
+		{"<<REGEX:^\\d{3}$>>", json.Number("123"), true},
+		{"<<REGEX:^foo>>", []byte("foobar"), true},
+		{"<<RANGE:1,10>>", json.Number("10"), true},
+		{"<<RANGE:1,10>>", json.Number("0.5"), false},
+		{"<<RANGE: -1.5, 1.5 >>", float64(-1.5), true},
+		{"<<APPROX:3.14,0.01>>", json.Number("3.1415"), true},
+		{"<<APPROX:3.14,0.01>>", json.Number("3.2"), false},
+		{"<<APPROX:3.14,0.01>>", nil, false},
+	}
+
+	for _, tt := range tests {
+		err := Match(tt.value, tt.placeholder)
+		if tt.matches && err != nil {
+			t.Fatalf("%v should match %s, it got %v", tt.value, tt.placeholder, err)
+		}
+		if !tt.matches && err == nil {
+			t.Fatalf("%v should not match %s", tt.value, tt.placeholder)
+		}
+	}
+}
+
+func TestMatch_InvalidPlaceholder(t *testing.T) {
+	placeholders := []string{"<<UUID:4>>", "<<REGEX:[>>", "<<RANGE:1>>", "<<RANGE:10,1>>", "<<APPROX:1,-1>>", "<<APPROX:a,1>>", "<<UNKNOWN>>"}
+
+	for _, placeholder := range placeholders {
+		err := Match("foo", placeholder)
+		if err == nil {
+			t.Fatalf("%s should be invalid", placeholder)
+		}
+	}
+}
+
+func TestIsPlaceholder(t *testing.T) {
+	tests := map[string]bool{
+		"<<UUID>>":       true,
+		"<<RANGE:1,10>>": true,
+		"<<PRESENCE>>":   false,
+		"<<UNKNOWN>>":    false,
+		"UUID":           false,
+		"id: <<UUID>>":   false,
+	}
+
+	for text, expected := range tests {
+		if IsPlaceholder(text) != expected {
+			t.Fatalf("IsPlaceholder(%s) should be %v", text, expected)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register("EVEN", Func(func(value interface{}) error {
+		n, err := number(value)
+		if err != nil {
+			return err
+		}
+		if int(n)%2 != 0 {
+			return errors.New("it's odd")
+		}
+		return nil
+	}))
+
+	RegisterFactory("PREFIX", func(args string) (Matcher, error) {
+		return Func(func(value interface{}) error {
+			text, _ := value.(string)
+			if !strings.HasPrefix(text, args) {
+				return errors.New("prefix not found")
+			}
+			return nil
+		}), nil
+	})
+
+	err := JSON(`{"count": 2, "id": "user_1"}`, `{"count": "<<EVEN>>", "id": "<<PREFIX:user_>>"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = JSON(`{"count": 3, "id": "user_1"}`, `{"count": "<<EVEN>>", "id": "<<PREFIX:user_>>"}`)
+	if err == nil || !strings.Contains(err.Error(), "value at '$.count' does not match <<EVEN>>: it's odd") {
+		t.Fatalf("it should fail due to the custom matcher, it got %v", err)
+	}
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	for _, name := range []string{"PRESENCE", "UNORDERED", "lower", ""} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("registering '%s' should panic", name)
+				}
+			}()
+			Register(name, Func(func(value interface{}) error { return nil }))
+		}()
+	}
+}
+
+func TestJSON(t *testing.T) {
+	actual := `{
+		"id": "0c3a3a55-91b2-4a35-9a0e-5c0e7a3c3d6f",
+		"createdAt": "2023-08-01T10:00:00Z",
+		"price": 3.1415,
+		"items": [{"quantity": 3}, {"quantity": 7}],
+		"tags": ["b", "a"],
+		"title": "foo"
+	}`
+
+	expected := `{
+		"id": "<<UUID>>",
+		"createdAt": "<<RFC3339>>",
+		"price": "<<APPROX:3.14,0.01>>",
+		"items": [{"quantity": "<<RANGE:1,5>>"}, {"quantity": "<<ANY_NUMBER>>"}],
+		"tags": ["<<UNORDERED>>", "a", "b"],
+		"title": "<<PRESENCE>>"
+	}`
+
+	err := JSON(actual, expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJSON_Mismatch(t *testing.T) {
+	err := JSON(
+		`{"id": "foo", "items": [{"quantity": 9}], "title": "bar"}`,
+		`{"id": "<<UUID>>", "items": [{"quantity": "<<RANGE:1,5>>"}], "title": "foo"}`,
+	)
+	if err == nil {
+		t.Fatal("it should fail due to the mismatches")
+	}
+
+	for _, message := range []string{
+		`value at '$.id' does not match <<UUID>>: "foo" is not a UUID`,
+		`value at '$.items[0].quantity' does not match <<RANGE:1,5>>: 9 is not between 1 and 5`,
+		`expected string at '$.title' to be 'foo' but was 'bar'`,
+	} {
+		if !strings.Contains(err.Error(), message) {
+			t.Fatalf("it should contain '%s', it got %v", message, err)
+		}
+	}
+
+	err = JSON(`{"id": "foo"}`, `{"id": "<<PRESENCE>>", "createdAt": "<<RFC3339>>"}`)
+	if err == nil || !strings.Contains(err.Error(), "createdAt") {
+		t.Fatalf("it should fail due to the missing field, it got %v", err)
+	}
+}
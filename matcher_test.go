@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"context"
+	goHTTP "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc"
+)
+
+func TestMatcher_HTTP(t *testing.T) {
+	handler := bodyHandler(`{"id": "0c3a3a55-91b2-4a35-9a0e-5c0e7a3c3d6f", "createdAt": "2023-08-01T10:00:00Z", "price": 3.1415}`)
+
+	err := Test(&HTTPTestCase{
+		Description: "TestMatcher_HTTP",
+		Handler:     handler,
+		Request:     call.Request{URL: "/orders/1"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       `{"id": "<<UUID>>", "createdAt": "<<RFC3339>>", "price": "<<APPROX:3.14,0.01>>"}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&HTTPTestCase{
+		Description: "TestMatcher_HTTP text",
+		Handler:     bodyHandler("0c3a3a55-91b2-4a35-9a0e-5c0e7a3c3d6f"),
+		Request:     call.Request{URL: "/orders"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusOK, Body: "<<UUID>>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestMatcher_HTTP mismatch",
+		Handler:     handler,
+		Request:     call.Request{URL: "/orders/1"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       `{"id": "<<UUID>>", "createdAt": "<<RFC3339>>", "price": "<<RANGE:4,5>>"}`,
+		},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "value at '$.price' does not match <<RANGE:4,5>>: 3.1415 is not between 4 and 5") {
+		t.Fatalf("it should fail due to the price, it got %v", result.Err())
+	}
+}
+
+func TestMatcher_Websocket(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(websocket.TextMessage, message)
+	}))
+	defer server.Close()
+
+	err := Test(&WebsocketTestCase{
+		Description: "TestMatcher_Websocket",
+		Call: call.Websocket{
+			Scheme:  call.WebsocketSchemeWS,
+			URL:     strings.TrimPrefix(server.URL, "http://"),
+			Path:    "/",
+			Message: "2023-08-01T10:00:00Z",
+		},
+		Receive: &expect.Message{Content: "<<RFC3339>>"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMatcher_GRPC(t *testing.T) {
+	// the interceptor replies with the message received
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return req, nil
+	}
+
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		chat.RegisterChatServiceServer(s, &Server{})
+	}, grpc.UnaryInterceptor(interceptor))
+
+	err := Test(&GRPCTestCase{
+		Description: "TestMatcher_GRPC",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 7, Body: "0c3a3a55-91b2-4a35-9a0e-5c0e7a3c3d6f"},
+		},
+		Output: expect.Output{
+			Message: `{"id": "<<RANGE:1,10>>", "body": "<<UUID>>"}`,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,22 @@
+package mock
+
+import "net/http"
+
+// Responder is called to return a mocked HTTP response for a request
+type Responder func(req *http.Request) (*http.Response, error)
+
+// Registry keeps mocked HTTP responders and how many times each one of them was called.
+// HTTP assertions register their responders in a registry and check the call count and errors on it.
+type Registry interface {
+	// RegisterResponder registers a responder for a method and URL.
+	// eg: GET https://jsonplaceholder.typicode.com/posts/1
+	RegisterResponder(method, url string, responder Responder)
+
+	// CallCount returns how many times the responder registered for a method and URL was called.
+	// It returns false if there is no responder registered for them.
+	CallCount(method, url string) (int, bool)
+
+	// Errors returns the errors returned by the responder registered for a method and URL,
+	// eg: when a request didn't match the expected body or header.
+	Errors(method, url string) []error
+}
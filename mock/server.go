@@ -0,0 +1,145 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// RecordedRequest is a request received by the mock server
+type RecordedRequest struct {
+	// Method of the request
+	Method string
+	// URL of the request, including the mock server URL
+	// eg: http://127.0.0.1:41234/posts?page=1
+	URL string
+	// Header of the request
+	Header http.Header
+	// Body of the request
+	Body string
+}
+
+// Server is a HTTP server listening on a local address that responds requests with the mocked responses registered in it.
+// Differently from the global httpmock transport, it can be used when the service being tested runs
+// in another process (eg: a binary or a container) or uses its own HTTP client.
+// The service being tested must be configured to send its requests to the server URL.
+type Server struct {
+	server    *httptest.Server
+	transport *Transport
+	requests  []RecordedRequest
+	mux       sync.Mutex
+}
+
+// NewServer creates and starts a new Server listening on a random port of the loopback interface
+func NewServer() *Server {
+	s := &Server{transport: NewTransport()}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// NewServerAt creates and starts a new Server listening on the address
+// eg: 0.0.0.0:9090
+func NewServerAt(addr string) (*Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &Server{transport: NewTransport()}
+	s.server = httptest.NewUnstartedServer(http.HandlerFunc(s.handle))
+	s.server.Listener.Close()
+	s.server.Listener = listener
+	s.server.Start()
+
+	return s, nil
+}
+
+// URL returns the base URL of the server
+// eg: http://127.0.0.1:41234
+func (s *Server) URL() string {
+	return s.server.URL
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// RegisterResponder registers a responder for a method and URL.
+// The URL can either be absolute or a path, which will be prefixed with the server URL (eg: /posts/1).
+func (s *Server) RegisterResponder(method, url string, responder Responder) {
+	s.transport.RegisterResponder(method, s.absoluteURL(url), responder)
+}
+
+// CallCount returns how many times the responder registered for a method and URL was called
+func (s *Server) CallCount(method, url string) (int, bool) {
+	return s.transport.CallCount(method, s.absoluteURL(url))
+}
+
+// Errors returns the errors returned by the responder registered for a method and URL
+func (s *Server) Errors(method, url string) []error {
+	return s.transport.Errors(method, s.absoluteURL(url))
+}
+
+// Requests returns all requests received by the server in the order they were received
+func (s *Server) Requests() []RecordedRequest {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	requests := make([]RecordedRequest, len(s.requests))
+	copy(requests, s.requests)
+	return requests
+}
+
+func (s *Server) handle(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("mock server failed to read request body: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	url := s.absoluteURL(req.URL.RequestURI())
+
+	s.mux.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: req.Method,
+		URL:    url,
+		Header: req.Header.Clone(),
+		Body:   string(body),
+	})
+	s.mux.Unlock()
+
+	mockReq, err := http.NewRequestWithContext(req.Context(), req.Method, url, bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("mock server failed to create request: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	mockReq.Header = req.Header
+
+	resp, err := s.transport.RoundTrip(mockReq)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("mock server failed to respond request: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+func (s *Server) absoluteURL(url string) string {
+	if strings.HasPrefix(url, "/") {
+		return s.URL() + url
+	}
+	return url
+}
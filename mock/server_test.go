@@ -0,0 +1,115 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestServer_RespondsRegisteredResponder(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	server.RegisterResponder(http.MethodPost, "/posts", func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"X-Foo": []string{"bar"}},
+			Body:       io.NopCloser(strings.NewReader("created")),
+		}, nil
+	})
+
+	resp, err := http.Post(server.URL()+"/posts", "text/plain", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status code should be %d but it got %d", http.StatusCreated, resp.StatusCode)
+	}
+
+	if resp.Header.Get("X-Foo") != "bar" {
+		t.Fatalf("header should be 'bar' but it got '%s'", resp.Header.Get("X-Foo"))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != "created" {
+		t.Fatalf("body should be 'created' but it got '%s'", string(body))
+	}
+
+	times, ok := server.CallCount(http.MethodPost, server.URL()+"/posts")
+	if !ok || times != 1 {
+		t.Fatalf("responder should have been called once but it was called %d times", times)
+	}
+}
+
+func TestServer_RecordsRequests(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodPut, server.URL()+"/posts/1?draft=true", strings.NewReader("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-Foo", "bar")
+
+	_, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests := server.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("server should have recorded 1 request but it got %d", len(requests))
+	}
+
+	recorded := requests[0]
+	if recorded.Method != http.MethodPut || recorded.URL != server.URL()+"/posts/1?draft=true" {
+		t.Fatalf("recorded request should be 'PUT %s/posts/1?draft=true' but it got '%s %s'", server.URL(), recorded.Method, recorded.URL)
+	}
+
+	if recorded.Body != "hello" || recorded.Header.Get("X-Foo") != "bar" {
+		t.Fatalf("recorded request should have body 'hello' and header 'bar' but it got '%s' and '%s'", recorded.Body, recorded.Header.Get("X-Foo"))
+	}
+}
+
+func TestServer_FailsWithoutResponder(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL() + "/unknown")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("status code should be %d but it got %d", http.StatusInternalServerError, resp.StatusCode)
+	}
+
+	_, ok := server.CallCount(http.MethodGet, "/unknown")
+	if ok {
+		t.Fatal("there should not be a call count for an unregistered responder")
+	}
+}
+
+func TestNewServerAt(t *testing.T) {
+	server, err := NewServerAt("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	if !strings.HasPrefix(server.URL(), "http://127.0.0.1:") {
+		t.Fatalf("server URL should be local but it got '%s'", server.URL())
+	}
+
+	_, err = NewServerAt("invalid address")
+	if err == nil {
+		t.Fatal("it should fail to listen on an invalid address")
+	}
+}
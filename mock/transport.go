@@ -0,0 +1,75 @@
+package mock
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/jarcoal/httpmock"
+)
+
+// Transport is a HTTP transport that only mocks the requests sent through it.
+// Differently from the global httpmock transport, it doesn't replace `http.DefaultTransport`,
+// so many test cases can use their own Transport at the same time (eg: with `t.Parallel()`).
+// The service being tested must send its requests using this transport (eg: with the `Client` function).
+type Transport struct {
+	transport *httpmock.MockTransport
+	errors    map[string][]error
+	mux       sync.Mutex
+}
+
+// NewTransport creates a new Transport without any responder registered
+func NewTransport() *Transport {
+	return &Transport{
+		transport: httpmock.NewMockTransport(),
+		errors:    map[string][]error{},
+	}
+}
+
+// RoundTrip responds a request with the responder registered for its method and URL.
+// It returns an error if no responder was registered for the request.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req)
+}
+
+// Client returns a new HTTP client that sends requests using the transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// RegisterResponder registers a responder for a method and URL, replacing the previous one and its errors
+func (t *Transport) RegisterResponder(method, url string, responder Responder) {
+	key := routeKey(method, url)
+
+	t.mux.Lock()
+	delete(t.errors, key)
+	t.mux.Unlock()
+
+	t.transport.RegisterResponder(method, url, func(req *http.Request) (*http.Response, error) {
+		resp, err := responder(req)
+		if err != nil {
+			t.mux.Lock()
+			t.errors[key] = append(t.errors[key], err)
+			t.mux.Unlock()
+		}
+		return resp, err
+	})
+}
+
+// CallCount returns how many times the responder registered for a method and URL was called
+func (t *Transport) CallCount(method, url string) (int, bool) {
+	times, ok := t.transport.GetCallCountInfo()[routeKey(method, url)]
+	return times, ok
+}
+
+// Errors returns the errors returned by the responder registered for a method and URL
+func (t *Transport) Errors(method, url string) []error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	errs := t.errors[routeKey(method, url)]
+	return append([]error(nil), errs...)
+}
+
+func routeKey(method, url string) string {
+	return method + " " + url
+}
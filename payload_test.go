@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"fmt"
+	"io"
+	goHTTP "net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+// payloadHandler replies with the content type and the content received
+func payloadHandler(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+	contentType := req.Header.Get("Content-Type")
+	w.Header().Set("X-Content-Type", contentType)
+
+	switch {
+	case contentType == "application/x-www-form-urlencoded":
+		req.ParseForm()
+		fmt.Fprintf(w, "name=%s tags=%s", req.PostForm.Get("name"), strings.Join(req.PostForm["tags"], ","))
+	case strings.HasPrefix(contentType, "multipart/form-data"):
+		err := req.ParseMultipartForm(1024)
+		if err != nil {
+			goHTTP.Error(w, err.Error(), goHTTP.StatusBadRequest)
+			return
+		}
+
+		fmt.Fprintf(w, "name=%s", req.MultipartForm.Value["name"][0])
+		for _, field := range []string{"avatar", "notes"} {
+			header := req.MultipartForm.File[field][0]
+			file, _ := header.Open()
+			content, _ := io.ReadAll(file)
+			fmt.Fprintf(w, " %s=%s(%s):%s", field, header.Filename, header.Header.Get("Content-Type"), content)
+		}
+	default:
+		content, _ := io.ReadAll(req.Body)
+		w.Write(content)
+	}
+}
+
+func TestHTTPPayload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "avatar.png")
+	err := os.WriteFile(path, []byte("png"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		payload     call.Payload
+		header      goHTTP.Header
+		contentType string
+		body        string
+	}{
+		{
+			name:        "form",
+			payload:     call.Form{"name": {"{{name}}"}, "tags": {"a", "b"}},
+			contentType: "application/x-www-form-urlencoded",
+			body:        "name=foo tags=a,b",
+		},
+		{
+			name: "multipart",
+			payload: call.Multipart{
+				Fields: url.Values{"name": {"{{name}}"}},
+				Files: []call.File{
+					{Field: "avatar", Path: path, ContentType: "image/png"},
+					{Field: "notes", Name: "notes.txt", Content: []byte("bar")},
+				},
+			},
+			body: "name=foo avatar=avatar.png(image/png):png notes=notes.txt(application/octet-stream):bar",
+		},
+		{
+			name:        "binary",
+			payload:     call.Binary{Path: path, ContentType: "image/png"},
+			contentType: "image/png",
+			body:        "png",
+		},
+		{
+			name:        "json",
+			payload:     call.JSON{Value: map[string]interface{}{"id": 1, "title": "foo"}},
+			contentType: "application/json",
+			body:        `{"id": 1, "title": "foo"}`,
+		},
+		{
+			name:        "content type in header",
+			payload:     call.JSON{Value: []int{1, 2}},
+			header:      goHTTP.Header{"Content-Type": []string{"application/vnd.api+json"}},
+			contentType: "application/vnd.api+json",
+			body:        "[1, 2]",
+		},
+	}
+
+	for _, tt := range tests {
+		header := goHTTP.Header{}
+		if tt.contentType != "" {
+			header.Set("X-Content-Type", tt.contentType)
+		}
+
+		err := Test(&Scenario{
+			Description: "TestHTTPPayload " + tt.name,
+			Vars:        map[string]string{"name": "foo"},
+			Steps: []Step{
+				{Test: &HTTPTestCase{
+					Handler: goHTTP.HandlerFunc(payloadHandler),
+					Request: call.Request{
+						URL:     "/",
+						Method:  goHTTP.MethodPost,
+						Header:  tt.header,
+						Payload: tt.payload,
+					},
+					Response: expect.Response{
+						StatusCode: goHTTP.StatusOK,
+						Header:     header,
+						Body:       tt.body,
+					},
+				}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+	}
+}
+
+func TestHTTPPayload_Invalid(t *testing.T) {
+	tests := map[string]call.Request{
+		"body and payload":  {URL: "/", Body: "foo", Payload: call.JSON{Value: "foo"}},
+		"file not found":    {URL: "/", Payload: call.Binary{Path: "testdata/unknown.png"}},
+		"file without path": {URL: "/", Payload: call.Multipart{Files: []call.File{{Field: "avatar"}}}},
+		"file without name": {URL: "/", Payload: call.Multipart{Files: []call.File{{Field: "avatar", Content: []byte("foo")}}}},
+		"invalid json":      {URL: "/", Payload: call.JSON{Value: func() {}}},
+	}
+
+	for name, request := range tests {
+		result := Evaluate(&HTTPTestCase{
+			Description: "TestHTTPPayload_Invalid " + name,
+			Handler:     goHTTP.HandlerFunc(payloadHandler),
+			Request:     request,
+			Response:    expect.Response{StatusCode: goHTTP.StatusOK},
+		})
+		if result.Passed() {
+			t.Fatalf("%s: it should fail", name)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lucasvmiguel/integration"
+)
+
+// JSON writes the results as a JSON document
+type JSON struct {
+	collector
+}
+
+type jsonReport struct {
+	Passed   int          `json:"passed"`
+	Failed   int          `json:"failed"`
+	Duration float64      `json:"duration"`
+	Results  []jsonResult `json:"results"`
+}
+
+type jsonResult struct {
+	Description string        `json:"description"`
+	Protocol    string        `json:"protocol,omitempty"`
+	Passed      bool          `json:"passed"`
+	Duration    float64       `json:"duration"`
+	Failures    []jsonFailure `json:"failures,omitempty"`
+	Steps       []jsonResult  `json:"steps,omitempty"`
+}
+
+type jsonFailure struct {
+	Phase     string `json:"phase,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Assertion int    `json:"assertion,omitempty"`
+	Step      int    `json:"step,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Error     string `json:"error"`
+	Expected  string `json:"expected,omitempty"`
+	Actual    string `json:"actual,omitempty"`
+	Diff      string `json:"diff,omitempty"`
+}
+
+// NewJSON creates a JSON reporter
+func NewJSON() *JSON {
+	return &JSON{}
+}
+
+// Write writes the results reported so far. Durations are written in seconds.
+func (j *JSON) Write(w io.Writer) error {
+	report := jsonReport{Results: []jsonResult{}}
+
+	for _, result := range j.Results() {
+		if result.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Duration += result.Duration.Seconds()
+		report.Results = append(report.Results, newJSONResult(result))
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(report)
+	if err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+
+	return nil
+}
+
+func newJSONResult(result *integration.Result) jsonResult {
+	r := jsonResult{
+		Description: result.Description,
+		Protocol:    result.Protocol,
+		Passed:      result.Passed(),
+		Duration:    result.Duration.Seconds(),
+	}
+
+	for _, failure := range result.Failures {
+		r.Failures = append(r.Failures, jsonFailure{
+			Phase:     string(failure.Phase),
+			Kind:      string(failure.Kind),
+			Assertion: failure.Assertion,
+			Step:      failure.Step,
+			Message:   failure.Message,
+			Error:     failure.Err.Error(),
+			Expected:  failure.Expected,
+			Actual:    failure.Actual,
+			Diff:      failure.Diff,
+		})
+	}
+
+	for _, step := range result.Steps {
+		r.Steps = append(r.Steps, newJSONResult(step))
+	}
+
+	return r
+}
@@ -0,0 +1,196 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/lucasvmiguel/integration"
+)
+
+// JUnit writes the results as JUnit XML.
+// Each scenario is written as a test suite with a test case per step,
+// other test cases are written in a test suite called Name.
+type JUnit struct {
+	collector
+
+	// Name of the test suite of the test cases that are not scenarios
+	// default: integration
+	Name string
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// NewJUnit creates a JUnit reporter
+func NewJUnit() *JUnit {
+	return &JUnit{}
+}
+
+// Write writes the results reported so far
+func (j *JUnit) Write(w io.Writer) error {
+	suites := junitTestSuites{}
+
+	others := junitTestSuite{Name: j.Name}
+	if others.Name == "" {
+		others.Name = "integration"
+	}
+	var othersDuration time.Duration
+	var total time.Duration
+
+	for i, result := range j.Results() {
+		total += result.Duration
+
+		if result.Protocol == "Scenario" {
+			suites.TestSuites = append(suites.TestSuites, junitScenario(result, i+1))
+			continue
+		}
+
+		others.TestCases = append(others.TestCases, junitCase(name(result, i+1), result.Protocol, result.Duration, result.Failures))
+		othersDuration += result.Duration
+	}
+
+	if len(others.TestCases) > 0 {
+		others.Time = seconds(othersDuration)
+		suites.TestSuites = append(suites.TestSuites, others)
+	}
+
+	for i, suite := range suites.TestSuites {
+		for _, c := range suite.TestCases {
+			suites.TestSuites[i].Tests++
+			if c.Failure != nil {
+				suites.TestSuites[i].Failures++
+			}
+		}
+		suites.Tests += suites.TestSuites[i].Tests
+		suites.Failures += suites.TestSuites[i].Failures
+	}
+	suites.Time = seconds(total)
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(suites)
+	if err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// junitScenario creates a test suite with a test case per step.
+// Failures that don't belong to a step that ran (eg: invalid scenario) are written as extra test cases.
+func junitScenario(result *integration.Result, position int) junitTestSuite {
+	suite := junitTestSuite{Name: name(result, position), Time: seconds(result.Duration)}
+
+	for i, step := range result.Steps {
+		failures := step.Failures
+		if step.Passed() {
+			failures = stepFailures(result, i+1)
+		}
+		suite.TestCases = append(suite.TestCases, junitCase(name(step, i+1), step.Protocol, step.Duration, failures))
+	}
+
+	for _, failure := range result.Failures {
+		if failure.Step > 0 && failure.Step <= len(result.Steps) {
+			continue
+		}
+
+		caseName := suite.Name
+		if failure.Step > 0 {
+			caseName = fmt.Sprintf("step %d", failure.Step)
+		}
+		suite.TestCases = append(suite.TestCases, junitCase(caseName, result.Protocol, 0, []integration.Failure{failure}))
+	}
+
+	return suite
+}
+
+func junitCase(name, protocol string, duration time.Duration, failures []integration.Failure) junitTestCase {
+	c := junitTestCase{Name: name, ClassName: protocol, Time: seconds(duration)}
+	if len(failures) == 0 {
+		return c
+	}
+
+	messages := []string{}
+	content := []string{}
+	for _, failure := range failures {
+		messages = append(messages, failure.Err.Error())
+		content = append(content, describe(failure))
+	}
+
+	c.Failure = &junitFailure{
+		Message: strings.Join(messages, "; "),
+		Type:    failures[0].Where(),
+		Content: strings.Join(content, "\n\n"),
+	}
+	return c
+}
+
+func stepFailures(result *integration.Result, step int) []integration.Failure {
+	failures := []integration.Failure{}
+	for _, failure := range result.Failures {
+		if failure.Step == step {
+			failures = append(failures, failure)
+		}
+	}
+	return failures
+}
+
+// describe returns a failure as text, including the expected and actual values and their diff
+func describe(failure integration.Failure) string {
+	lines := []string{}
+	if failure.Phase != "" {
+		lines = append(lines, fmt.Sprintf("%s: %s", failure.Where(), failure.Message))
+	}
+	if failure.Kind == integration.KindTimeout {
+		lines = append(lines, "timeout")
+	}
+	lines = append(lines, failure.Err.Error())
+
+	if failure.Expected != "" || failure.Actual != "" {
+		lines = append(lines, "expected: "+failure.Expected, "actual: "+failure.Actual)
+	}
+	if failure.Diff != "" {
+		lines = append(lines, strings.TrimSuffix(failure.Diff, "\n"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func seconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
@@ -0,0 +1,53 @@
+// Package report writes the results of test cases as JUnit XML, JSON or TAP.
+//
+// Reporters are registered with integration.AddReporter and written after the test cases run:
+//
+//	junit := report.NewJUnit()
+//	integration.AddReporter(junit)
+//	// run test cases
+//	junit.Write(file)
+package report
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/lucasvmiguel/integration"
+)
+
+// collector keeps the results reported. It can be used by test cases running in parallel.
+type collector struct {
+	mux     sync.Mutex
+	results []*integration.Result
+}
+
+// Report stores the result of a test case
+func (c *collector) Report(result *integration.Result) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.results = append(c.results, result)
+}
+
+// Results returns the results reported so far
+func (c *collector) Results() []*integration.Result {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	results := make([]*integration.Result, len(c.results))
+	copy(results, c.results)
+	return results
+}
+
+// name returns the description of a result, or a name based on its protocol if it's empty
+func name(result *integration.Result, position int) string {
+	if result.Description != "" {
+		return result.Description
+	}
+
+	if result.Protocol != "" {
+		return fmt.Sprintf("%s test case %d", result.Protocol, position)
+	}
+
+	return fmt.Sprintf("test case %d", position)
+}
@@ -0,0 +1,158 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lucasvmiguel/integration"
+)
+
+func results() []*integration.Result {
+	return []*integration.Result{
+		{Description: "get post", Protocol: "HTTP", Duration: 120 * time.Millisecond},
+		{
+			Description: "create post # 2",
+			Protocol:    "HTTP",
+			Duration:    time.Second,
+			Failures: []integration.Failure{
+				{
+					Phase:    integration.PhaseAssert,
+					Message:  "failed to assert HTTP response",
+					Err:      errors.New("response status code should be 201 it got 200"),
+					Expected: "201",
+					Actual:   "200",
+				},
+				{
+					Phase:     integration.PhaseAssertion,
+					Assertion: 1,
+					Message:   "failed to assert",
+					Err:       errors.New("HTTP request 'GET https://example.com' has never been called"),
+				},
+				{
+					Phase:    integration.PhaseAssert,
+					Message:  "failed to assert HTTP response",
+					Err:      errors.New("response body is a JSON. response body does not match"),
+					Expected: `{"title":"foo"}`,
+					Actual:   `{"title":"bar"}`,
+					Diff:     "--- expected\n+++ actual\n@@ $.title @@\n-\"foo\"\n+\"bar\"\n",
+				},
+			},
+		},
+		{
+			Description: "posts",
+			Protocol:    "Scenario",
+			Steps: []*integration.Result{
+				{Description: "create", Protocol: "HTTP"},
+				{Protocol: "HTTP"},
+			},
+			Failures: []integration.Failure{
+				{Phase: integration.PhaseAssert, Step: 2, Message: "failed to capture 'id' in step 2", Err: errors.New("path 'id' not found")},
+			},
+		},
+	}
+}
+
+func TestJUnit(t *testing.T) {
+	junit := NewJUnit()
+	for _, result := range results() {
+		junit.Report(result)
+	}
+
+	out := &bytes.Buffer{}
+	err := junit.Write(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	suites := junitTestSuites{}
+	err = xml.Unmarshal(out.Bytes(), &suites)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if suites.Tests != 4 || suites.Failures != 2 || len(suites.TestSuites) != 2 {
+		t.Fatalf("it should have 4 test cases and 2 failures in 2 test suites, it got %s", out)
+	}
+
+	scenario := suites.TestSuites[0]
+	if scenario.Name != "posts" || scenario.TestCases[1].Name != "HTTP test case 2" || scenario.TestCases[1].Failure == nil {
+		t.Fatalf("scenario step should have failed, it got %s", out)
+	}
+
+	others := suites.TestSuites[1]
+	failure := others.TestCases[1].Failure
+	if others.Name != "integration" || failure == nil || failure.Type != "assert" || !strings.Contains(failure.Content, "expected: 201\nactual: 200") {
+		t.Fatalf("test case should have failed with expected and actual values, it got %s", out)
+	}
+	if !strings.Contains(failure.Content, "@@ $.title @@\n-\"foo\"\n+\"bar\"") {
+		t.Fatalf("test case should have failed with the diff, it got %s", out)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	j := NewJSON()
+	for _, result := range results() {
+		j.Report(result)
+	}
+
+	out := &bytes.Buffer{}
+	err := j.Write(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := jsonReport{}
+	err = json.Unmarshal(out.Bytes(), &report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Passed != 1 || report.Failed != 2 || len(report.Results) != 3 {
+		t.Fatalf("it should have 1 passed and 2 failed results, it got %s", out)
+	}
+
+	failure := report.Results[1].Failures[0]
+	if failure.Phase != "assert" || failure.Expected != "201" || failure.Actual != "200" {
+		t.Fatalf("it should have the failure with expected and actual values, it got %s", out)
+	}
+
+	if report.Results[1].Failures[2].Diff != results()[1].Failures[2].Diff {
+		t.Fatalf("it should have the failure with the diff, it got %s", out)
+	}
+
+	if len(report.Results[2].Steps) != 2 || report.Results[2].Failures[0].Step != 2 {
+		t.Fatalf("it should have the steps of the scenario, it got %s", out)
+	}
+}
+
+func TestTAP(t *testing.T) {
+	tap := NewTAP()
+	for _, result := range results() {
+		tap.Report(result)
+	}
+
+	out := &bytes.Buffer{}
+	err := tap.Write(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"TAP version 13\n1..3\n",
+		"ok 1 - get post\n",
+		"not ok 2 - create post \\# 2\n  ---\n  protocol: HTTP\n  duration: 1s\n  failures:\n",
+		"    - phase: assertion 1\n",
+		"      diff: |\n        --- expected\n        +++ actual\n",
+		"not ok 3 - posts\n",
+	}
+	for _, e := range expected {
+		if !strings.Contains(out.String(), e) {
+			t.Fatalf("report should contain %q, it got %s", e, out)
+		}
+	}
+}
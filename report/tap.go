@@ -0,0 +1,98 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TAP writes the results in the Test Anything Protocol version 13.
+// Failures are written as a YAML block below the test that failed.
+type TAP struct {
+	collector
+}
+
+type tapDiagnostic struct {
+	Protocol string       `yaml:"protocol,omitempty"`
+	Duration string       `yaml:"duration"`
+	Failures []tapFailure `yaml:"failures"`
+}
+
+type tapFailure struct {
+	Phase    string `yaml:"phase,omitempty"`
+	Kind     string `yaml:"kind,omitempty"`
+	Step     int    `yaml:"step,omitempty"`
+	Message  string `yaml:"message,omitempty"`
+	Error    string `yaml:"error"`
+	Expected string `yaml:"expected,omitempty"`
+	Actual   string `yaml:"actual,omitempty"`
+	Diff     string `yaml:"diff,omitempty"`
+}
+
+// NewTAP creates a TAP reporter
+func NewTAP() *TAP {
+	return &TAP{}
+}
+
+// Write writes the results reported so far
+func (t *TAP) Write(w io.Writer) error {
+	results := t.Results()
+
+	out := &bytes.Buffer{}
+	fmt.Fprintln(out, "TAP version 13")
+	fmt.Fprintf(out, "1..%d\n", len(results))
+
+	for i, result := range results {
+		description := strings.ReplaceAll(name(result, i+1), "#", `\#`)
+
+		if result.Passed() {
+			fmt.Fprintf(out, "ok %d - %s\n", i+1, description)
+			continue
+		}
+
+		fmt.Fprintf(out, "not ok %d - %s\n", i+1, description)
+
+		diagnostic := tapDiagnostic{Protocol: result.Protocol, Duration: result.Duration.String()}
+		for _, failure := range result.Failures {
+			phase := ""
+			if failure.Phase != "" {
+				phase = failure.Where()
+			}
+
+			diagnostic.Failures = append(diagnostic.Failures, tapFailure{
+				Phase:    phase,
+				Kind:     string(failure.Kind),
+				Step:     failure.Step,
+				Message:  failure.Message,
+				Error:    failure.Err.Error(),
+				Expected: failure.Expected,
+				Actual:   failure.Actual,
+				Diff:     failure.Diff,
+			})
+		}
+
+		content := &bytes.Buffer{}
+		encoder := yaml.NewEncoder(content)
+		encoder.SetIndent(2)
+		err := encoder.Encode(diagnostic)
+		if err != nil {
+			return fmt.Errorf("failed to write TAP report: %w", err)
+		}
+
+		fmt.Fprintln(out, "  ---")
+		for _, line := range strings.Split(strings.TrimSuffix(content.String(), "\n"), "\n") {
+			fmt.Fprintln(out, "  "+line)
+		}
+		fmt.Fprintln(out, "  ...")
+	}
+
+	_, err := out.WriteTo(w)
+	if err != nil {
+		return fmt.Errorf("failed to write TAP report: %w", err)
+	}
+
+	return nil
+}
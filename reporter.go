@@ -0,0 +1,50 @@
+package integration
+
+import "sync"
+
+// Reporter observes the result of every test case run by Test or Evaluate
+// eg: report.NewJUnit()
+type Reporter interface {
+	Report(result *Result)
+}
+
+// registeredReporter is a pointer to a reporter, so it can be removed even if the reporter isn't comparable
+type registeredReporter struct {
+	reporter Reporter
+}
+
+var reporters = struct {
+	sync.RWMutex
+	list []*registeredReporter
+}{}
+
+// AddReporter registers a reporter that observes all test cases that run from now on.
+// It returns a function that removes the reporter.
+func AddReporter(reporter Reporter) (remove func()) {
+	registered := &registeredReporter{reporter: reporter}
+
+	reporters.Lock()
+	reporters.list = append(reporters.list, registered)
+	reporters.Unlock()
+
+	return func() {
+		reporters.Lock()
+		defer reporters.Unlock()
+
+		for i, r := range reporters.list {
+			if r == registered {
+				reporters.list = append(reporters.list[:i:i], reporters.list[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func report(result *Result) {
+	reporters.RLock()
+	defer reporters.RUnlock()
+
+	for _, r := range reporters.list {
+		r.reporter.Report(result)
+	}
+}
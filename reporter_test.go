@@ -0,0 +1,57 @@
+package integration
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+type reporterFunc func(result *Result)
+
+func (f reporterFunc) Report(result *Result) {
+	f(result)
+}
+
+func TestAddReporter(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	mux := sync.Mutex{}
+	reported := []*Result{}
+	remove := AddReporter(reporterFunc(func(result *Result) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		if result.Description == "TestAddReporter" {
+			reported = append(reported, result)
+		}
+	}))
+
+	testCase := &HTTPTestCase{
+		Description: "TestAddReporter",
+		Request:     call.Request{URL: server.URL + "/posts/2"},
+		Response:    expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`},
+	}
+
+	err := Test(testCase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remove()
+
+	err = Test(testCase)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+
+	if len(reported) != 1 || reported[0].Protocol != "HTTP" || !reported[0].Passed() {
+		t.Fatalf("it should report the test case only once, it got %v", reported)
+	}
+}
@@ -0,0 +1,253 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Phase is the part of a test case where a failure happened
+type Phase string
+
+const (
+	// PhaseValidate is when the test case fields are validated
+	PhaseValidate Phase = "validate"
+	// PhaseSetup is when the assertions are set up
+	PhaseSetup Phase = "setup"
+	// PhaseCall is when the HTTP endpoint, GRPC function or Websocket server is called
+	PhaseCall Phase = "call"
+	// PhaseAssert is when the response is compared with the expected one
+	PhaseAssert Phase = "assert"
+	// PhaseAssertion is when an assertion runs
+	PhaseAssertion Phase = "assertion"
+)
+
+// Kind is the kind of a failure
+type Kind string
+
+const (
+	// KindFailure is when the test case couldn't run or received something different from what was expected
+	KindFailure Kind = "failure"
+	// KindTimeout is when a call or the test case exceeded its deadline
+	KindTimeout Kind = "timeout"
+)
+
+// Failure describes why a test case failed
+type Failure struct {
+	// Phase where the failure happened
+	Phase Phase
+
+	// Kind of the failure
+	// eg: timeout
+	Kind Kind
+
+	// Assertion is the position (starting at 1) of the assertion that failed in the assertion phase
+	Assertion int
+
+	// Step is the position (starting at 1) of the scenario step that failed
+	Step int
+
+	// Message describes what was being done when the failure happened
+	// eg: failed to assert HTTP response
+	Message string
+
+	// Err is the reason of the failure
+	Err error
+
+	// Expected is the value the test case expected, when the failure is a mismatch
+	// eg: the expected response body
+	Expected string
+
+	// Actual is the value the test case received, when the failure is a mismatch
+	// eg: the response body received
+	Actual string
+
+	// Diff is a unified diff of Actual from Expected, when the failure is a body or message mismatch
+	// eg: a hunk for each path of a JSON body that differs
+	Diff string
+}
+
+// Where returns the phase of the failure including the assertion position
+// eg: assert or assertion 2
+func (f Failure) Where() string {
+	if f.Phase == PhaseAssertion {
+		return fmt.Sprintf("%s %d", f.Phase, f.Assertion)
+	}
+	return string(f.Phase)
+}
+
+// Result describes how a test case ran
+type Result struct {
+	// Description of the test case
+	Description string
+
+	// Protocol of the test case: HTTP, GRPC, Websocket or Scenario
+	Protocol string
+
+	// Duration the test case took to run
+	Duration time.Duration
+
+	// Failures found while running the test case. It's empty when the test case passed.
+	Failures []Failure
+
+	// Steps are the results of each step when the test case is a scenario
+	Steps []*Result
+
+	// response is what the test case received, the steps of a scenario capture values from it.
+	// It's nil if the test case failed before receiving a response.
+	response *response
+
+	start time.Time
+}
+
+// evaluator is implemented by test cases that can return a result with all failures found
+type evaluator interface {
+	evaluate(ctx context.Context) *Result
+}
+
+// mismatchError is returned when a value received is different from the expected one
+type mismatchError struct {
+	err      error
+	expected string
+	actual   string
+	// diff is a unified diff of the actual value from the expected one, if it's a body or message
+	diff string
+}
+
+func (e *mismatchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *mismatchError) Unwrap() error {
+	return e.err
+}
+
+// Evaluate runs a test case, notifies the reporters and returns its result with all failures found
+func Evaluate(tester Tester) *Result {
+	return EvaluateContext(context.Background(), tester)
+}
+
+// EvaluateContext runs a test case with a context, like Evaluate.
+// The test case stops when the context is canceled or its deadline is exceeded.
+func EvaluateContext(ctx context.Context, tester Tester) *Result {
+	result := evaluateTester(ctx, tester)
+	report(result)
+	return result
+}
+
+func evaluateTester(ctx context.Context, tester Tester) *Result {
+	e, ok := tester.(evaluator)
+	if ok {
+		return e.evaluate(ctx)
+	}
+
+	result := newResult("", "")
+	defer result.finish()
+
+	var err error
+	contextTester, ok := tester.(ContextTester)
+	if ok {
+		err = contextTester.TestContext(ctx)
+	} else {
+		err = tester.Test()
+	}
+
+	if err != nil {
+		result.Failures = append(result.Failures, Failure{Kind: kind(err), Err: err})
+	}
+
+	return result
+}
+
+// Passed returns true if the test case didn't fail
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Err returns an error describing all failures, or nil if the test case passed
+func (r *Result) Err() error {
+	if r.Passed() {
+		return nil
+	}
+
+	if len(r.Failures) == 1 && r.Failures[0].Message == "" {
+		return r.Failures[0].Err
+	}
+
+	return errors.New(strings.Join(r.messages(), "\n"))
+}
+
+// messages returns a message for each failure
+func (r *Result) messages() []string {
+	messages := []string{}
+	for _, failure := range r.Failures {
+		if failure.Message == "" {
+			messages = append(messages, failure.Err.Error())
+			continue
+		}
+		messages = append(messages, withDiff(errString(failure.Err, r.Description, failure.Message), failure.Diff))
+	}
+	return messages
+}
+
+func newResult(description, protocol string) *Result {
+	return &Result{Description: description, Protocol: protocol, start: time.Now()}
+}
+
+// finish sets the duration of a result created by newResult
+func (r *Result) finish() {
+	r.Duration = time.Since(r.start)
+}
+
+// fail adds a failure to the result and returns it
+func (r *Result) fail(phase Phase, message string, err error) *Result {
+	failure := Failure{Phase: phase, Kind: kind(err), Message: message, Err: err}
+
+	var mismatch *mismatchError
+	if errors.As(err, &mismatch) {
+		failure.Expected = mismatch.expected
+		failure.Actual = mismatch.actual
+		failure.Diff = mismatch.diff
+	}
+
+	r.Failures = append(r.Failures, failure)
+	return r
+}
+
+// failAssertion adds a failure of an assertion to the result
+func (r *Result) failAssertion(position int, err error) {
+	r.fail(PhaseAssertion, "failed to assert", err)
+	r.Failures[len(r.Failures)-1].Assertion = position
+}
+
+// failStep adds a failure of a scenario step to the result and returns it
+func (r *Result) failStep(step int, phase Phase, message string, err error) *Result {
+	r.fail(phase, message, err)
+	r.Failures[len(r.Failures)-1].Step = step
+	return r
+}
+
+// kind returns KindTimeout if the error was caused by an exceeded deadline
+func kind(err error) Kind {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return KindTimeout
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return KindTimeout
+	}
+
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if errors.As(err, &grpcErr) && grpcErr.GRPCStatus().Code() == codes.DeadlineExceeded {
+		return KindTimeout
+	}
+
+	return KindFailure
+}
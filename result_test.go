@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/assertion"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/mock"
+)
+
+type testerFunc func() error
+
+func (f testerFunc) Test() error {
+	return f()
+}
+
+func TestEvaluate_CollectsAllFailures(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestEvaluate_CollectsAllFailures",
+		Request:     call.Request{URL: server.URL + "/posts/2"},
+		Response: expect.Response{
+			StatusCode: http.StatusCreated,
+			Body:       `{"id": 3, "title": "foo2"}`,
+		},
+		Assertions: []assertion.Assertion{
+			&assertion.HTTP{Request: expect.Request{URL: "https://jsonplaceholder.typicode.com/posts/1"}},
+			&assertion.HTTP{Request: expect.Request{URL: "https://jsonplaceholder.typicode.com/posts/2"}},
+		},
+		Mock: mock.NewTransport(),
+	})
+
+	if result.Passed() || len(result.Failures) != 4 {
+		t.Fatalf("it should have 4 failures, it got %v", result.Failures)
+	}
+
+	status := result.Failures[0]
+	if status.Phase != PhaseAssert || status.Expected != "201" || status.Actual != "200" {
+		t.Fatalf("it should fail due to the status code, it got %+v", status)
+	}
+
+	body := result.Failures[1]
+	if body.Phase != PhaseAssert || body.Expected != `{"id": 3, "title": "foo2"}` || body.Actual != `{"id": 2, "title": "foo2"}` {
+		t.Fatalf("it should fail due to the response body, it got %+v", body)
+	}
+
+	for i, failure := range result.Failures[2:] {
+		if failure.Where() != fmt.Sprintf("assertion %d", i+1) {
+			t.Fatalf("it should fail due to assertion %d, it got %s", i+1, failure.Where())
+		}
+	}
+
+	if result.Description != "TestEvaluate_CollectsAllFailures" || result.Duration <= 0 {
+		t.Fatalf("it should have description and duration, it got %+v", result)
+	}
+
+	err := result.Err()
+	if err == nil || !strings.Contains(err.Error(), "TestEvaluate_CollectsAllFailures: failed to assert HTTP response : response status code should be 201 it got 200") {
+		t.Fatalf("it should return all failures as error, it got %v", err)
+	}
+}
+
+func TestEvaluate_CallFailure(t *testing.T) {
+	result := Evaluate(&HTTPTestCase{
+		Request:  call.Request{URL: "http://localhost:1/posts"},
+		Response: expect.Response{StatusCode: http.StatusOK},
+	})
+
+	if len(result.Failures) != 1 || result.Failures[0].Phase != PhaseCall {
+		t.Fatalf("it should fail on call phase, it got %v", result.Failures)
+	}
+}
+
+func TestEvaluate_Scenario(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	result := Evaluate(&Scenario{
+		Description: "TestEvaluate_Scenario",
+		Vars:        map[string]string{"baseURL": server.URL},
+		Steps: []Step{
+			{Test: &HTTPTestCase{Request: call.Request{URL: "{{baseURL}}/posts/2"}, Response: expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`}}},
+			{Test: &HTTPTestCase{Request: call.Request{URL: "{{baseURL}}/posts/3"}, Response: expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`}}},
+		},
+	})
+
+	if len(result.Steps) != 2 || !result.Steps[0].Passed() || result.Steps[1].Passed() {
+		t.Fatalf("it should have the result of each step, it got %v", result.Err())
+	}
+
+	failure := result.Failures[0]
+	if failure.Step != 2 || failure.Phase != PhaseAssert || failure.Expected != "200" || failure.Actual != "404" {
+		t.Fatalf("it should fail on step 2, it got %+v", failure)
+	}
+}
+
+func TestEvaluate_Tester(t *testing.T) {
+	result := Evaluate(testerFunc(func() error { return nil }))
+	if !result.Passed() || result.Err() != nil {
+		t.Fatalf("it should pass, it got %v", result.Failures)
+	}
+
+	result = Evaluate(testerFunc(func() error { return errors.New("custom error") }))
+	if result.Passed() || result.Err().Error() != "custom error" {
+		t.Fatalf("it should fail with the tester error, it got %v", result.Err())
+	}
+}
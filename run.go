@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"fmt"
+	"testing"
+)
+
+// errorer is the part of testing.T used to report failures
+type errorer interface {
+	Errorf(format string, args ...any)
+}
+
+// Run runs each test case as a subtest of t named by its description.
+// Every failure of a test case is reported with t.Errorf, so one failure doesn't hide the others.
+// Websocket connections opened by the test cases are closed when t finishes.
+// Mocks are not closed, since they are created by the caller and can be shared by other test cases.
+func Run(t *testing.T, testers ...Tester) {
+	t.Helper()
+	run(t, false, testers)
+}
+
+// RunParallel runs each test case as a parallel subtest of t, like Run.
+// Test cases with HTTP assertions must have a Mock to run in parallel.
+func RunParallel(t *testing.T, testers ...Tester) {
+	t.Helper()
+	run(t, true, testers)
+}
+
+func run(t *testing.T, parallel bool, testers []Tester) {
+	t.Helper()
+
+	for i, tester := range testers {
+		tester := tester
+
+		name := fmt.Sprintf("test case %d", i+1)
+		if description := describe(tester); description != "" {
+			name = description
+		}
+
+		t.Run(name, func(st *testing.T) {
+			st.Helper()
+
+			if parallel {
+				st.Parallel()
+			}
+
+			result := Evaluate(tester)
+			cleanup(t, tester)
+			fail(st, result)
+		})
+	}
+}
+
+// fail reports each failure of a result
+func fail(t errorer, result *Result) {
+	for _, message := range result.messages() {
+		t.Errorf("%s", message)
+	}
+}
+
+// cleanup closes the Websocket connections opened by a test case when t finishes
+func cleanup(t *testing.T, tester Tester) {
+	switch tc := tester.(type) {
+	case *WebsocketTestCase:
+		conn := tc.Connection()
+		if conn != nil && conn != tc.Call.Connection && !tc.Call.CloseConnectionAfterCall {
+			t.Cleanup(func() { conn.Close() })
+		}
+	case *Scenario:
+		for _, step := range tc.Steps {
+			cleanup(t, step.Test)
+		}
+	}
+}
+
+// describe returns the description of a test case
+func describe(tester Tester) string {
+	switch tc := tester.(type) {
+	case *HTTPTestCase:
+		return tc.Description
+	case *GRPCTestCase:
+		return tc.Description
+	case *WebsocketTestCase:
+		return tc.Description
+	case *Scenario:
+		return tc.Description
+	}
+	return ""
+}
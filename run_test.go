@@ -0,0 +1,102 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/mock"
+)
+
+type errorerFunc func(format string, args ...any)
+
+func (f errorerFunc) Errorf(format string, args ...any) {
+	f(format, args...)
+}
+
+func TestRun(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	wsCase := &WebsocketTestCase{
+		Description: "send message",
+		Call: call.Websocket{
+			URL:     fmt.Sprintf("localhost:%d", 8090),
+			Path:    "/handler-infinite",
+			Message: "hello",
+		},
+		Receive: &expect.Message{Content: "hello"},
+	}
+
+	t.Run("cases", func(t *testing.T) {
+		Run(t,
+			&HTTPTestCase{
+				Description: "get post",
+				Request:     call.Request{URL: server.URL + "/posts/2"},
+				Response:    expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`},
+			},
+			wsCase,
+		)
+
+		if wsCase.Connection().Send(websocket.TextMessage, []byte("hello")) != nil {
+			t.Fatal("connection should be open until the test finishes")
+		}
+	})
+
+	if wsCase.Connection().Send(websocket.TextMessage, []byte("hello")) == nil {
+		t.Fatal("connection should have been closed when the test finished")
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	mocks := []*mock.Server{mock.NewServer(), mock.NewServer()}
+	for _, m := range mocks {
+		defer m.Close()
+	}
+
+	t.Run("cases", func(t *testing.T) {
+		testers := []Tester{}
+		for i, m := range mocks {
+			testers = append(testers, &HTTPTestCase{
+				Description: fmt.Sprintf("get post %d", i),
+				Request:     call.Request{URL: server.URL + "/posts/2"},
+				Response:    expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`},
+				Mock:        m,
+			})
+		}
+
+		RunParallel(t, testers...)
+	})
+
+	for _, m := range mocks {
+		resp, err := http.Get(m.URL())
+		if err != nil {
+			t.Fatalf("mock server should be open until its owner closes it, it got %v", err)
+		}
+		resp.Body.Close()
+	}
+}
+
+func TestFail(t *testing.T) {
+	messages := []string{}
+	fail(errorerFunc(func(format string, args ...any) {
+		messages = append(messages, fmt.Sprintf(format, args...))
+	}), &Result{
+		Description: "TestFail",
+		Failures: []Failure{
+			{Phase: PhaseAssert, Message: "failed to assert HTTP response", Err: errors.New("wrong status")},
+			{Phase: PhaseAssertion, Assertion: 1, Message: "failed to assert", Err: errors.New("never called")},
+		},
+	})
+
+	if len(messages) != 2 || messages[1] != "TestFail: failed to assert : never called" {
+		t.Fatalf("it should report each failure, it got %v", messages)
+	}
+}
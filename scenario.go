@@ -0,0 +1,479 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/lucasvmiguel/integration/assertion"
+	"github.com/lucasvmiguel/integration/auth"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/jsonpath"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Scenario describes test cases that will run in order sharing variables.
+// Any string of a call, an expectation or an assertion can use a variable with the syntax {{name}},
+// which is replaced by its value before the test case runs.
+type Scenario struct {
+	// Description describes a scenario
+	// It can be really useful to understand which tests are breaking
+	Description string
+
+	// Vars are the variables available since the first step
+	// eg: map[string]string{"baseURL": "http://localhost:8080"}
+	Vars map[string]string
+
+	// Steps are the test cases that will run in order
+	Steps []Step
+
+	variables map[string]string
+}
+
+// Step describes a test case that will run in a scenario
+type Step struct {
+	// Test is the test case that will run in the step
+	// eg: &HTTPTestCase{}
+	Test Tester
+
+	// Capture stores values of the test case response in variables that can be used by the next steps
+	Capture []Capture
+}
+
+// Capture stores a value of a test case response in a variable
+type Capture struct {
+	// Name of the variable the value will be stored in
+	// eg: postID
+	Name string
+
+	// Path of the value in the JSON HTTP response body, Websocket message or GRPC response message.
+	// If Path and Header are empty, the whole body or message is stored.
+	// eg: data.items[0].id
+	Path string
+
+	// Header of the HTTP response or GRPC header metadata the value will be captured from. Path is ignored if Header is set.
+	// eg: Location
+	Header string
+}
+
+// interpolator is implemented by test cases that can have variables replaced
+type interpolator interface {
+	interpolate(vars map[string]string) (Tester, error)
+}
+
+// response is what a test case received
+type response struct {
+	header http.Header
+	body   []byte
+}
+
+var variablePattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+
+// Test runs all steps of a scenario
+func (s *Scenario) Test() error {
+	return s.TestContext(context.Background())
+}
+
+// TestContext runs all steps of a scenario, the context is passed to each step
+func (s *Scenario) TestContext(ctx context.Context) error {
+	return s.evaluate(ctx).Err()
+}
+
+func (s *Scenario) evaluate(ctx context.Context) *Result {
+	result := newResult(s.Description, "Scenario")
+	defer result.finish()
+
+	err := s.validate()
+	if err != nil {
+		return result.fail(PhaseValidate, "failed to validate scenario", err)
+	}
+
+	vars := map[string]string{}
+	for name, value := range s.Vars {
+		vars[name] = value
+	}
+	s.variables = vars
+
+	for i, step := range s.Steps {
+		tester := step.Test
+
+		in, ok := tester.(interpolator)
+		if ok {
+			tester, err = in.interpolate(vars)
+			if err != nil {
+				return result.failStep(i+1, PhaseSetup, fmt.Sprintf("failed to interpolate step %d", i+1), err)
+			}
+		}
+
+		stepResult := evaluateTester(ctx, tester)
+		result.Steps = append(result.Steps, stepResult)
+
+		if !stepResult.Passed() {
+			for _, failure := range stepResult.Failures {
+				err := failure.Err
+				if failure.Message != "" {
+					err = errors.New(errString(failure.Err, stepResult.Description, failure.Message))
+				}
+
+				result.failStep(i+1, failure.Phase, fmt.Sprintf("failed to run step %d", i+1), err)
+				last := &result.Failures[len(result.Failures)-1]
+				last.Assertion = failure.Assertion
+				last.Expected = failure.Expected
+				last.Actual = failure.Actual
+				last.Diff = failure.Diff
+			}
+			return result
+		}
+
+		// the connection is kept in the step, so it can be reused after the scenario
+		original, ok := step.Test.(*WebsocketTestCase)
+		if ok && original != tester {
+			original.connection = tester.(*WebsocketTestCase).connection
+		}
+
+		for _, c := range step.Capture {
+			value, err := capture(stepResult.response, c)
+			if err != nil {
+				return result.failStep(i+1, PhaseAssert, fmt.Sprintf("failed to capture '%s' in step %d", c.Name, i+1), err)
+			}
+			vars[c.Name] = value
+		}
+	}
+
+	return result
+}
+
+// Variables returns the variables of the last run, including the captured ones
+func (s *Scenario) Variables() map[string]string {
+	vars := map[string]string{}
+	for name, value := range s.variables {
+		vars[name] = value
+	}
+	return vars
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Steps) == 0 {
+		return errors.New("steps are required")
+	}
+
+	for i, step := range s.Steps {
+		if step.Test == nil {
+			return fmt.Errorf("test of step %d is required", i+1)
+		}
+
+		for _, c := range step.Capture {
+			if !variablePattern.MatchString(fmt.Sprintf("{{%s}}", c.Name)) {
+				return fmt.Errorf("capture name '%s' of step %d is invalid", c.Name, i+1)
+			}
+		}
+	}
+
+	return nil
+}
+
+func capture(resp *response, c Capture) (string, error) {
+	if resp == nil {
+		return "", errors.New("test case does not support captures")
+	}
+
+	if c.Header != "" {
+		values := resp.header.Values(c.Header)
+		if len(values) == 0 {
+			return "", fmt.Errorf("header '%s' was not received", c.Header)
+		}
+		return values[0], nil
+	}
+
+	if c.Path == "" {
+		return string(resp.body), nil
+	}
+
+	// the numbers are kept as they were received, so large IDs don't lose precision
+	document, err := decodeJSON(resp.body)
+	if err != nil {
+		return "", fmt.Errorf("response is not a JSON: %w", err)
+	}
+
+	value, err := jsonpath.Get(document, c.Path)
+	if err != nil {
+		return "", err
+	}
+
+	str, ok := value.(string)
+	if ok {
+		return str, nil
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal captured value: %w", err)
+	}
+	return string(valueJSON), nil
+}
+
+// interpolation replaces variables keeping the first error found
+type interpolation struct {
+	vars map[string]string
+	err  error
+}
+
+func (i *interpolation) string(s string) string {
+	return variablePattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := variablePattern.FindStringSubmatch(match)[1]
+		value, ok := i.vars[name]
+		if !ok {
+			if i.err == nil {
+				i.err = fmt.Errorf("variable '%s' is not defined", name)
+			}
+			return match
+		}
+		return value
+	})
+}
+
+func (i *interpolation) header(header http.Header) http.Header {
+	if header == nil {
+		return nil
+	}
+
+	replaced := http.Header{}
+	for key, values := range header {
+		for _, value := range values {
+			replaced[key] = append(replaced[key], i.string(value))
+		}
+	}
+	return replaced
+}
+
+func (i *interpolation) cookies(cookies []expect.Cookie) []expect.Cookie {
+	if cookies == nil {
+		return nil
+	}
+
+	replaced := []expect.Cookie{}
+	for _, cookie := range cookies {
+		cookie.Value = i.string(cookie.Value)
+		replaced = append(replaced, cookie)
+	}
+	return replaced
+}
+
+// auth replaces the variables of the basic and bearer credentials, other providers are kept as they are
+func (i *interpolation) auth(provider auth.Provider) auth.Provider {
+	switch provider := provider.(type) {
+	case auth.Basic:
+		return auth.Basic{Username: i.string(provider.Username), Password: i.string(provider.Password)}
+	case auth.Bearer:
+		return auth.Bearer{Token: i.string(provider.Token)}
+	}
+	return provider
+}
+
+// payload replaces the variables of the form and multipart fields, other payloads are kept as they are
+func (i *interpolation) payload(payload call.Payload) call.Payload {
+	switch payload := payload.(type) {
+	case call.Form:
+		return call.Form(i.values(url.Values(payload)))
+	case call.Multipart:
+		payload.Fields = i.values(payload.Fields)
+		return payload
+	}
+	return payload
+}
+
+func (i *interpolation) values(values url.Values) url.Values {
+	if values == nil {
+		return nil
+	}
+
+	replaced := url.Values{}
+	for key, list := range values {
+		for _, value := range list {
+			replaced[key] = append(replaced[key], i.string(value))
+		}
+	}
+	return replaced
+}
+
+func (i *interpolation) fields(fields []expect.Field) []expect.Field {
+	if fields == nil {
+		return nil
+	}
+
+	replaced := []expect.Field{}
+	for _, field := range fields {
+		field.Path = i.string(field.Path)
+		field.Value = i.value(field.Value)
+		replaced = append(replaced, field)
+	}
+	return replaced
+}
+
+func (i *interpolation) metadata(md metadata.MD) metadata.MD {
+	if md == nil {
+		return nil
+	}
+
+	replaced := metadata.MD{}
+	for key, values := range md {
+		for _, value := range values {
+			replaced[key] = append(replaced[key], i.string(value))
+		}
+	}
+	return replaced
+}
+
+func (i *interpolation) value(v any) any {
+	str, ok := v.(string)
+	if !ok {
+		return v
+	}
+	return i.string(str)
+}
+
+// message replaces the variables of the string fields of a proto message, or of a JSON message.
+// The message passed as param is not modified.
+func (i *interpolation) message(m interface{}) interface{} {
+	if s, ok := m.(string); ok {
+		return i.string(s)
+	}
+
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return m
+	}
+
+	clone := proto.Clone(msg)
+	i.protoMessage(clone.ProtoReflect())
+	return clone
+}
+
+// messages replaces the variables of a list of proto messages like message
+func (i *interpolation) messages(messages []interface{}) []interface{} {
+	if messages == nil {
+		return nil
+	}
+
+	replaced := make([]interface{}, len(messages))
+	for j, m := range messages {
+		replaced[j] = i.message(m)
+	}
+	return replaced
+}
+
+func (i *interpolation) protoMessage(m protoreflect.Message) {
+	fields := []protoreflect.FieldDescriptor{}
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		value := m.Get(fd)
+		isMessage := fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+
+		switch {
+		case fd.IsList():
+			list := value.List()
+			for j := 0; j < list.Len(); j++ {
+				if fd.Kind() == protoreflect.StringKind {
+					list.Set(j, protoreflect.ValueOfString(i.string(list.Get(j).String())))
+				} else if isMessage {
+					i.protoMessage(list.Get(j).Message())
+				}
+			}
+		case fd.IsMap():
+			keys := []protoreflect.MapKey{}
+			value.Map().Range(func(key protoreflect.MapKey, _ protoreflect.Value) bool {
+				keys = append(keys, key)
+				return true
+			})
+
+			valueKind := fd.MapValue().Kind()
+			for _, key := range keys {
+				if valueKind == protoreflect.StringKind {
+					value.Map().Set(key, protoreflect.ValueOfString(i.string(value.Map().Get(key).String())))
+				} else if valueKind == protoreflect.MessageKind {
+					i.protoMessage(value.Map().Get(key).Message())
+				}
+			}
+		case fd.Kind() == protoreflect.StringKind:
+			m.Set(fd, protoreflect.ValueOfString(i.string(value.String())))
+		case isMessage:
+			i.protoMessage(value.Message())
+		}
+	}
+}
+
+func (i *interpolation) query(query call.Query) call.Query {
+	replaced := query
+	replaced.Statement = i.string(query.Statement)
+
+	if query.Params != nil {
+		replaced.Params = make([]any, len(query.Params))
+		for j, param := range query.Params {
+			replaced.Params[j] = i.value(param)
+		}
+	}
+
+	return replaced
+}
+
+func (i *interpolation) result(result expect.Result) expect.Result {
+	if result == nil {
+		return nil
+	}
+
+	replaced := make(expect.Result, len(result))
+	for j, row := range result {
+		replaced[j] = map[string]any{}
+		for key, value := range row {
+			replaced[j][key] = i.value(value)
+		}
+	}
+	return replaced
+}
+
+// assertions replaces the variables of the HTTP, SQL and Eventually assertions.
+// The assertions passed as param are not modified.
+func (i *interpolation) assertions(assertions []assertion.Assertion) []assertion.Assertion {
+	if assertions == nil {
+		return nil
+	}
+
+	replaced := make([]assertion.Assertion, len(assertions))
+	for j, a := range assertions {
+		replaced[j] = i.assertion(a)
+	}
+	return replaced
+}
+
+func (i *interpolation) assertion(a assertion.Assertion) assertion.Assertion {
+	switch a := a.(type) {
+	case *assertion.HTTP:
+		httpAssertion := *a
+		httpAssertion.Request.URL = i.string(a.Request.URL)
+		httpAssertion.Request.Header = i.header(a.Request.Header)
+		httpAssertion.Request.Body = i.string(a.Request.Body)
+		httpAssertion.Response.Body = i.string(a.Response.Body)
+		return &httpAssertion
+	case *assertion.SQL:
+		sqlAssertion := *a
+		sqlAssertion.Query = i.query(a.Query)
+		sqlAssertion.Result = i.result(a.Result)
+		return &sqlAssertion
+	case *assertion.Eventually:
+		eventually := *a
+		eventually.Assertion = i.assertion(a.Assertion)
+		return &eventually
+	}
+	return a
+}
@@ -0,0 +1,299 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/assertion"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func postsServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && req.URL.Path == "/posts" {
+			w.Header().Set("Location", "/posts/2")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 2, "title": "foo2"}`))
+			return
+		}
+
+		if req.Method == http.MethodGet && req.URL.Path == "/posts/2" {
+			w.Write([]byte(`{"id": 2, "title": "foo2"}`))
+			return
+		}
+
+		http.NotFound(w, req)
+	}))
+}
+
+func TestScenario_HTTP(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	db, _ := connectToDatabase()
+	scenario := &Scenario{
+		Description: "TestScenario_HTTP",
+		Vars:        map[string]string{"baseURL": server.URL},
+		Steps: []Step{
+			{
+				Test: &HTTPTestCase{
+					Description: "create post",
+					Request: call.Request{
+						URL:    "{{baseURL}}/posts",
+						Method: http.MethodPost,
+					},
+					Response: expect.Response{
+						StatusCode: http.StatusCreated,
+						Body:       `{"id": "<<PRESENCE>>", "title": "<<PRESENCE>>"}`,
+					},
+				},
+				Capture: []Capture{
+					{Name: "id", Path: "id"},
+					{Name: "title", Path: "$.title"},
+					{Name: "location", Header: "Location"},
+				},
+			},
+			{
+				Test: &HTTPTestCase{
+					Description: "get post",
+					Request: call.Request{
+						URL: "{{baseURL}}{{location}}",
+					},
+					Response: expect.Response{
+						StatusCode: http.StatusOK,
+						Body:       `{"id": {{id}}, "title": "{{ title }}"}`,
+					},
+					Assertions: []assertion.Assertion{
+						&assertion.SQL{
+							DB: db,
+							Query: call.Query{
+								Statement: "SELECT id, title FROM products WHERE id = ?",
+								Params:    []any{"{{id}}"},
+							},
+							Result: expect.Result{
+								{"id": "{{id}}", "title": "{{title}}"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := Test(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := scenario.Variables()
+	if vars["id"] != "2" || vars["location"] != "/posts/2" || vars["baseURL"] != server.URL {
+		t.Fatalf("variables should have been captured, it got %v", vars)
+	}
+}
+
+func TestScenario_GRPC(t *testing.T) {
+	c, err := client()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&Scenario{
+		Description: "TestScenario_GRPC",
+		Vars:        map[string]string{"errorBody": errMessage},
+		Steps: []Step{
+			{
+				Test: &GRPCTestCase{
+					Description: "say hello",
+					Call: call.Call{
+						ServiceClient: c,
+						Function:      "SayHello",
+						Message:       &chat.Message{Id: 1, Body: "Hello From Client!"},
+					},
+					Output: expect.Output{
+						Message: &chat.Message{Id: 1, Body: "Hello From the Server!", Comment: "<<PRESENCE>>"},
+					},
+					Assertions: []assertion.Assertion{
+						&assertion.HTTP{
+							Request: expect.Request{URL: "https://jsonplaceholder.typicode.com/posts/1"},
+						},
+					},
+				},
+				Capture: []Capture{
+					{Name: "comment", Path: "comment"},
+				},
+			},
+			{
+				Test: &GRPCTestCase{
+					Description: "say hello with captured comment",
+					Call: call.Call{
+						ServiceClient: c,
+						Function:      "SayHello",
+						Message:       &chat.Message{Id: 1, Body: "{{comment}}"},
+					},
+					Output: expect.Output{
+						Message: &chat.Message{Id: 1, Body: "Hello From the Server!", Comment: "{{comment}}"},
+					},
+					Assertions: []assertion.Assertion{
+						&assertion.HTTP{
+							Request: expect.Request{URL: "https://jsonplaceholder.typicode.com/posts/1"},
+						},
+					},
+				},
+			},
+			{
+				Test: &GRPCTestCase{
+					Description: "say hello with error",
+					Call: call.Call{
+						ServiceClient: c,
+						Function:      "SayHello",
+						Message:       &chat.Message{Body: "{{errorBody}}"},
+					},
+					Output: expect.Output{
+						Err: status.New(codes.Unavailable, errMessage),
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScenario_Websocket(t *testing.T) {
+	first := &WebsocketTestCase{
+		Description: "send token",
+		Call: call.Websocket{
+			URL:     fmt.Sprintf("localhost:%d", 8090),
+			Path:    "/handler-infinite",
+			Message: `{"token": "abc"}`,
+		},
+		Receive: &expect.Message{
+			Content: `{"token": "abc"}`,
+		},
+	}
+
+	err := Test(&Scenario{
+		Description: "TestScenario_Websocket",
+		Steps: []Step{
+			{
+				Test:    first,
+				Capture: []Capture{{Name: "token", Path: "token"}, {Name: "message"}},
+			},
+			{
+				Test: &WebsocketTestCase{
+					Description: "send captured token",
+					Call: call.Websocket{
+						URL:     fmt.Sprintf("localhost:%d", 8090),
+						Path:    "/handler-infinite",
+						Message: `{{token}} {{message}}`,
+					},
+					Receive: &expect.Message{
+						Content: `abc {"token": "abc"}`,
+					},
+				},
+			},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Connection() == nil {
+		t.Fatal("connection of the step should be kept after the scenario")
+	}
+}
+
+func TestScenario_UndefinedVariable(t *testing.T) {
+	err := Test(&Scenario{
+		Description: "TestScenario_UndefinedVariable",
+		Steps: []Step{
+			{
+				Test: &HTTPTestCase{
+					Request:  call.Request{URL: "{{unknown}}/posts"},
+					Response: expect.Response{StatusCode: http.StatusOK},
+				},
+			},
+		},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "variable 'unknown' is not defined") {
+		t.Fatalf("it should fail due to an undefined variable, it got %v", err)
+	}
+}
+
+func TestScenario_CaptureNotFound(t *testing.T) {
+	server := postsServer()
+	defer server.Close()
+
+	err := Test(&Scenario{
+		Description: "TestScenario_CaptureNotFound",
+		Steps: []Step{
+			{
+				Test: &HTTPTestCase{
+					Request:  call.Request{URL: server.URL + "/posts/2"},
+					Response: expect.Response{StatusCode: http.StatusOK, Body: `{"id": 2, "title": "foo2"}`},
+				},
+				Capture: []Capture{{Name: "author", Path: "author.name"}},
+			},
+		},
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "failed to capture 'author' in step 1") {
+		t.Fatalf("it should fail due to a path not found, it got %v", err)
+	}
+}
+
+func TestScenario_CaptureLargeNumber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(`{"id": 1234567890123456789}`))
+	}))
+	defer server.Close()
+
+	scenario := &Scenario{
+		Description: "TestScenario_CaptureLargeNumber",
+		Steps: []Step{
+			{
+				Test: &HTTPTestCase{
+					Request:  call.Request{URL: server.URL + "/posts"},
+					Response: expect.Response{StatusCode: http.StatusOK, Body: `{"id": "<<PRESENCE>>"}`},
+				},
+				Capture: []Capture{{Name: "id", Path: "id"}},
+			},
+		},
+	}
+
+	err := Test(scenario)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := scenario.Variables()["id"]
+	if id != "1234567890123456789" {
+		t.Fatalf("id should be '1234567890123456789' it got '%s'", id)
+	}
+}
+
+func TestScenario_InvalidSteps(t *testing.T) {
+	err := Test(&Scenario{Description: "TestScenario_InvalidSteps"})
+	if err == nil {
+		t.Fatal("it should fail due to no steps")
+	}
+
+	err = Test(&Scenario{
+		Description: "TestScenario_InvalidSteps",
+		Steps:       []Step{{Test: &HTTPTestCase{}, Capture: []Capture{{Name: "invalid name"}}}},
+	})
+	if err == nil {
+		t.Fatal("it should fail due to an invalid capture name")
+	}
+}
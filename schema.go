@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// schemaURL is the URL of a schema set by its content
+const schemaURL = "schema.json"
+
+// compileSchema compiles the schema of the file or the content
+func compileSchema(schema *expect.Schema) (*jsonschema.Schema, error) {
+	if (schema.File == "") == (schema.Content == "") {
+		return nil, errors.New("schema should have either a file or a content")
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+
+	url := schemaURL
+	if schema.File != "" {
+		path, err := filepath.Abs(schema.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find schema file: %w", err)
+		}
+		url = path
+	} else {
+		err := compiler.AddResource(url, strings.NewReader(schema.Content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read schema: %w", err)
+		}
+	}
+
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	return compiled, nil
+}
+
+// assertSchema returns the violations of the schema by the JSON content, or nil if it's valid.
+// name describes the content in the failure (eg: response body).
+func assertSchema(name string, schema *expect.Schema, content []byte) error {
+	compiled, err := compileSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	// numbers are decoded as json.Number, so they keep their precision
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var document interface{}
+	err = decoder.Decode(&document)
+	if err != nil {
+		return fmt.Errorf("%s is not a JSON, it can't be validated with the schema: %w", name, err)
+	}
+
+	err = compiled.Validate(document)
+	if err == nil {
+		return nil
+	}
+
+	var validationErr *jsonschema.ValidationError
+	if !errors.As(err, &validationErr) {
+		return fmt.Errorf("failed to validate %s with the schema: %w", name, err)
+	}
+
+	expected := schema.File
+	if expected == "" {
+		expected = schema.Content
+	}
+
+	return &mismatchError{
+		err:      fmt.Errorf("%s does not match the schema: %s", name, strings.Join(schemaViolations(validationErr), "; ")),
+		expected: expected,
+		actual:   string(content),
+	}
+}
+
+// schemaViolations returns the path of each value that violates the schema and why
+// eg: /items/0/price: must be >= 0 but found -1
+func schemaViolations(err *jsonschema.ValidationError) []string {
+	if len(err.Causes) == 0 {
+		location := err.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		return []string{fmt.Sprintf("%s: %s", location, err.Message)}
+	}
+
+	violations := []string{}
+	for _, cause := range err.Causes {
+		violations = append(violations, schemaViolations(cause)...)
+	}
+	return violations
+}
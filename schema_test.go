@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"context"
+	goHTTP "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/internal/chat"
+	"google.golang.org/grpc"
+)
+
+func bodyHandler(body string) goHTTP.Handler {
+	return goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	})
+}
+
+func TestSchema_HTTP(t *testing.T) {
+	err := Test(&HTTPTestCase{
+		Description: "TestSchema_HTTP",
+		Handler:     bodyHandler(`{"id": 1, "title": "foo", "tags": ["bar"]}`),
+		Request:     call.Request{URL: "/posts/1"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Schema:     &expect.Schema{File: "testdata/post.schema.json"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = Test(&HTTPTestCase{
+		Description: "TestSchema_HTTP content",
+		Handler:     bodyHandler(`[1, 2, 3]`),
+		Request:     call.Request{URL: "/numbers"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Body:       `[1, 2, 3]`,
+			Schema:     &expect.Schema{Content: `{"type": "array", "items": {"type": "number"}}`},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&HTTPTestCase{
+		Description: "TestSchema_HTTP violation",
+		Handler:     bodyHandler(`{"id": 0, "tags": ["bar", 2]}`),
+		Request:     call.Request{URL: "/posts/1"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Schema:     &expect.Schema{File: "testdata/post.schema.json"},
+		},
+	})
+	if result.Passed() {
+		t.Fatal("it should fail due to the schema")
+	}
+
+	message := result.Err().Error()
+	for _, violation := range []string{"response body does not match the schema", "/id:", "/tags/1:", "title"} {
+		if !strings.Contains(message, violation) {
+			t.Fatalf("it should contain '%s', it got %s", violation, message)
+		}
+	}
+
+	result = Evaluate(&HTTPTestCase{
+		Description: "TestSchema_HTTP not JSON",
+		Handler:     bodyHandler(`foo`),
+		Request:     call.Request{URL: "/"},
+		Response: expect.Response{
+			StatusCode: goHTTP.StatusOK,
+			Schema:     &expect.Schema{Content: `{"type": "object"}`},
+		},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "response body is not a JSON") {
+		t.Fatalf("it should fail due to the body, it got %v", result.Err())
+	}
+}
+
+func TestSchema_Invalid(t *testing.T) {
+	schemas := []*expect.Schema{
+		{},
+		{File: "testdata/post.schema.json", Content: `{}`},
+		{File: "testdata/not-found.schema.json"},
+		{Content: `{"type": 1}`},
+	}
+
+	for _, schema := range schemas {
+		result := Evaluate(&HTTPTestCase{
+			Description: "TestSchema_Invalid",
+			Handler:     bodyHandler(`{}`),
+			Request:     call.Request{URL: "/"},
+			Response:    expect.Response{StatusCode: goHTTP.StatusOK, Schema: schema},
+		})
+		if result.Passed() || result.Failures[0].Phase != PhaseValidate {
+			t.Fatalf("it should fail validation for %+v, it got %v", schema, result.Err())
+		}
+	}
+}
+
+func TestSchema_Websocket(t *testing.T) {
+	server := httptest.NewServer(goHTTP.HandlerFunc(func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		upgrader := websocket.Upgrader{}
+		c, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer c.Close()
+
+		_, message, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.WriteMessage(websocket.TextMessage, message)
+	}))
+	defer server.Close()
+
+	err := Test(&WebsocketTestCase{
+		Description: "TestSchema_Websocket",
+		Call: call.Websocket{
+			Scheme:  call.WebsocketSchemeWS,
+			URL:     strings.TrimPrefix(server.URL, "http://"),
+			Path:    "/",
+			Message: `{"id": 1, "title": "foo"}`,
+		},
+		Receive: &expect.Message{
+			Schema: &expect.Schema{File: "testdata/post.schema.json"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&WebsocketTestCase{
+		Description: "TestSchema_Websocket violation",
+		Call: call.Websocket{
+			Scheme:  call.WebsocketSchemeWS,
+			URL:     strings.TrimPrefix(server.URL, "http://"),
+			Path:    "/",
+			Message: `{"id": "1", "title": "foo"}`,
+		},
+		Receive: &expect.Message{
+			Schema: &expect.Schema{File: "testdata/post.schema.json"},
+		},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "/id:") {
+		t.Fatalf("it should fail due to the schema, it got %v", result.Err())
+	}
+}
+
+func TestSchema_GRPC(t *testing.T) {
+	// the interceptor replies with the body of the message received
+	interceptor := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return &chat.Message{Id: req.(*chat.Message).Id, Body: req.(*chat.Message).Body}, nil
+	}
+
+	conn := ServeGRPCServices(t, func(s *grpc.Server) {
+		chat.RegisterChatServiceServer(s, &Server{})
+	}, grpc.UnaryInterceptor(interceptor))
+
+	schema := &expect.Schema{Content: `{
+		"type": "object",
+		"required": ["id", "body"],
+		"properties": {"body": {"type": "string", "minLength": 3}}
+	}`}
+
+	err := Test(&GRPCTestCase{
+		Description: "TestSchema_GRPC",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "foo"},
+		},
+		Output: expect.Output{Schema: schema},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := Evaluate(&GRPCTestCase{
+		Description: "TestSchema_GRPC violation",
+		Call: call.Call{
+			ServiceClient: chat.NewChatServiceClient(conn),
+			Function:      "SayHello",
+			Message:       &chat.Message{Id: 1, Body: "fo"},
+		},
+		Output: expect.Output{Schema: schema},
+	})
+	if result.Passed() || !strings.Contains(result.Err().Error(), "message does not match the schema: /body:") {
+		t.Fatalf("it should fail due to the schema, it got %v", result.Err())
+	}
+}
@@ -0,0 +1,28 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"sync"
+)
+
+// Session is shared by HTTP test cases to keep the cookies received and send default headers,
+// so a test case can log in and the next ones are authenticated.
+// The zero value is ready to use.
+type Session struct {
+	// Header is sent with the requests of the session, the header of a request overrides it
+	// eg: authorization=Bearer token
+	Header http.Header
+
+	once sync.Once
+	jar  http.CookieJar
+}
+
+// Jar returns the cookie jar of the session
+func (s *Session) Jar() http.CookieJar {
+	s.once.Do(func() {
+		// cookiejar.New only fails with invalid options
+		s.jar, _ = cookiejar.New(nil)
+	})
+	return s.jar
+}
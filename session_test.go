@@ -0,0 +1,77 @@
+package integration
+
+import (
+	goHTTP "net/http"
+	"net/url"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/expect"
+)
+
+func sessionHandler() goHTTP.Handler {
+	mux := goHTTP.NewServeMux()
+	mux.HandleFunc("/login", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		goHTTP.SetCookie(w, &goHTTP.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true})
+		w.WriteHeader(goHTTP.StatusNoContent)
+	})
+	mux.HandleFunc("/me", func(w goHTTP.ResponseWriter, req *goHTTP.Request) {
+		cookie, err := req.Cookie("session")
+		if err != nil {
+			w.WriteHeader(goHTTP.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-Client", req.Header.Get("X-Client"))
+		w.Header().Set("X-Request-Id", req.Header.Get("X-Request-Id"))
+		w.Write([]byte(cookie.Value))
+	})
+	return mux
+}
+
+func TestSession(t *testing.T) {
+	handler := sessionHandler()
+	session := &Session{Header: goHTTP.Header{"X-Client": []string{"test"}, "X-Request-Id": []string{"default"}}}
+
+	err := Test(&Scenario{
+		Description: "TestSession",
+		Steps: []Step{
+			{Test: &HTTPTestCase{
+				Handler:  handler,
+				Session:  session,
+				Request:  call.Request{URL: "/login", Method: goHTTP.MethodPost},
+				Response: expect.Response{StatusCode: goHTTP.StatusNoContent},
+			}},
+			{Test: &HTTPTestCase{
+				Handler: handler,
+				Session: session,
+				Request: call.Request{
+					URL:    "/me",
+					Header: goHTTP.Header{"x-request-id": []string{"1"}},
+				},
+				Response: expect.Response{
+					StatusCode: goHTTP.StatusOK,
+					Header:     goHTTP.Header{"X-Client": []string{"test"}, "X-Request-Id": []string{"1"}},
+					Body:       "abc",
+				},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cookies := session.Jar().Cookies(&url.URL{Scheme: "http", Host: "localhost", Path: "/"})
+	if len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Fatalf("session should have the cookie received, it has %v", cookies)
+	}
+
+	err = Test(&HTTPTestCase{
+		Description: "TestSession without session",
+		Handler:     handler,
+		Request:     call.Request{URL: "/me"},
+		Response:    expect.Response{StatusCode: goHTTP.StatusUnauthorized},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
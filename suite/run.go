@@ -0,0 +1,483 @@
+package suite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"regexp"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/lucasvmiguel/integration"
+	"github.com/lucasvmiguel/integration/assertion"
+	"github.com/lucasvmiguel/integration/auth"
+	"github.com/lucasvmiguel/integration/call"
+	"github.com/lucasvmiguel/integration/contract"
+	"github.com/lucasvmiguel/integration/expect"
+	"github.com/lucasvmiguel/integration/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+var messageTypes = map[string]int{
+	"":       websocket.TextMessage,
+	"text":   websocket.TextMessage,
+	"binary": websocket.BinaryMessage,
+	"ping":   websocket.PingMessage,
+}
+
+// variablePattern matches the variables of the suite, as the ones of a scenario (eg: {{baseURL}})
+var variablePattern = regexp.MustCompile(`{{\s*([A-Za-z_][A-Za-z0-9_]*)\s*}}`)
+
+// codeNames are the GRPC status codes by name (eg: Unavailable)
+var codeNames = map[string]codes.Code{}
+
+func init() {
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		codeNames[code.String()] = code
+	}
+}
+
+// Run runs all cases of the suite in order, stopping on the first one that fails.
+// The vars passed as param override the variables of the suite.
+func (s *Suite) Run(vars map[string]string) error {
+	return s.RunContext(context.Background(), vars)
+}
+
+// RunContext runs all cases of the suite like Run, the context is passed to each case
+func (s *Suite) RunContext(ctx context.Context, vars map[string]string) error {
+	databases, err := s.openDatabases()
+	defer func() {
+		for _, db := range databases {
+			db.Close()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]string{}
+	for name, value := range s.Vars {
+		variables[name] = value
+	}
+
+	var registry mock.Registry
+	if s.Mock != nil {
+		server, err := s.startMock()
+		if err != nil {
+			return err
+		}
+		defer server.Close()
+
+		registry = server
+		variables["mockURL"] = server.URL()
+	}
+
+	for name, value := range vars {
+		variables[name] = value
+	}
+
+	scenario := &integration.Scenario{
+		Description: s.Description,
+		Vars:        variables,
+	}
+
+	// the jar is shared by all cases, so cookies received by a case are sent by the next ones
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+
+	// the provider of the suite is shared by all cases, so OAuth2 tokens are cached
+	provider := s.Auth.provider(nil, variables)
+
+	// the contract is loaded once, so its coverage has the operations of all cases
+	s.spec = nil
+	if s.Contract != "" {
+		s.spec, err = contract.Load(s.Contract)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, c := range s.Cases {
+		client := s.Client
+		if c.HTTP != nil && c.HTTP.Client != nil {
+			client = c.HTTP.Client
+		}
+
+		tester, err := c.tester(databases, registry, client.options(jar), c.auth().provider(provider, variables), s.spec)
+		if err != nil {
+			return fmt.Errorf("failed to create case %d: %w", i+1, err)
+		}
+
+		step := integration.Step{Test: tester}
+		for _, capture := range c.Capture {
+			step.Capture = append(step.Capture, integration.Capture(capture))
+		}
+		scenario.Steps = append(scenario.Steps, step)
+	}
+
+	return integration.TestContext(ctx, scenario)
+}
+
+// Coverage returns the operations of the contract exercised by the last run, or nil if the suite has no contract
+func (s *Suite) Coverage() *contract.Coverage {
+	if s.spec == nil {
+		return nil
+	}
+
+	coverage := s.spec.Coverage()
+	return &coverage
+}
+
+func (s *Suite) openDatabases() (map[string]*sql.DB, error) {
+	databases := map[string]*sql.DB{}
+	for name, database := range s.Databases {
+		db, err := sql.Open(database.Driver, database.DSN)
+		if err != nil {
+			return databases, fmt.Errorf("failed to open database '%s': %w", name, err)
+		}
+		databases[name] = db
+	}
+	return databases, nil
+}
+
+func (s *Suite) startMock() (*mock.Server, error) {
+	if s.Mock.Address == "" {
+		return mock.NewServer(), nil
+	}
+
+	server, err := mock.NewServerAt(s.Mock.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start mock server: %w", err)
+	}
+	return server, nil
+}
+
+func (c Case) tester(databases map[string]*sql.DB, registry mock.Registry, client call.ClientOptions, provider auth.Provider, spec *contract.Spec) (integration.Tester, error) {
+	assertions := []assertion.Assertion{}
+	for _, a := range c.Assertions {
+		assertions = append(assertions, a.assertion(databases))
+	}
+
+	if c.HTTP != nil {
+		return &integration.HTTPTestCase{
+			Description: c.Description,
+			Request: call.Request{
+				URL:     c.HTTP.Request.URL,
+				Method:  c.HTTP.Request.Method,
+				Header:  header(c.HTTP.Request.Header),
+				Body:    string(c.HTTP.Request.Body),
+				Timeout: time.Duration(c.HTTP.Request.Timeout),
+				Auth:    provider,
+				Payload: c.HTTP.payload(),
+			},
+			Response: expect.Response{
+				StatusCode: c.HTTP.Response.Status,
+				Header:     header(c.HTTP.Response.Header),
+				Body:       string(c.HTTP.Response.Body),
+				Cookies:    cookies(c.HTTP.Response.Cookies),
+				Fields:     fields(c.HTTP.Response.Fields),
+				Schema:     c.HTTP.Response.Schema.schema(),
+			},
+			Assertions:    assertions,
+			Mock:          registry,
+			ClientOptions: client,
+			Contract:      spec,
+		}, nil
+	}
+
+	if c.GRPC != nil {
+		return c.GRPC.tester(c.Description, assertions, registry, provider), nil
+	}
+
+	messageType, ok := messageTypes[c.Websocket.Type]
+	if !ok {
+		return nil, fmt.Errorf("invalid websocket message type '%s'", c.Websocket.Type)
+	}
+
+	testCase := &integration.WebsocketTestCase{
+		Description: c.Description,
+		Call: call.Websocket{
+			URL:                      c.Websocket.URL,
+			Path:                     c.Websocket.Path,
+			Scheme:                   call.WebsocketScheme(c.Websocket.Scheme),
+			Header:                   header(c.Websocket.Header),
+			Auth:                     provider,
+			Message:                  string(c.Websocket.Message),
+			MessageType:              messageType,
+			CloseConnectionAfterCall: c.Websocket.Close,
+			Timeout:                  time.Duration(c.Websocket.Timeout),
+		},
+		Assertions: assertions,
+		Mock:       registry,
+	}
+
+	if c.Websocket.Receive != nil {
+		testCase.Receive = &expect.Message{
+			Content: string(c.Websocket.Receive.Content),
+			Schema:  c.Websocket.Receive.Schema.schema(),
+			Timeout: time.Duration(c.Websocket.Receive.Timeout),
+		}
+	}
+
+	return testCase, nil
+}
+
+// payload returns the form, multipart or file payload of the request, or nil if the body is used
+func (h *HTTP) payload() call.Payload {
+	r := h.Request
+	switch {
+	case r.Form != nil:
+		return call.Form(values(r.Form))
+	case r.Multipart != nil:
+		multipart := call.Multipart{Fields: values(r.Multipart.Fields)}
+		for _, file := range r.Multipart.Files {
+			multipart.Files = append(multipart.Files, call.File{
+				Field:       file.Field,
+				Name:        file.Name,
+				Path:        file.Path,
+				ContentType: file.ContentType,
+			})
+		}
+		return multipart
+	case r.File != nil:
+		return call.Binary{Path: r.File.Path, ContentType: r.File.ContentType}
+	}
+	return nil
+}
+
+func values(fields map[string]string) url.Values {
+	values := url.Values{}
+	for key, value := range fields {
+		values.Set(key, value)
+	}
+	return values
+}
+
+// provider returns the auth provider, or the fallback if there is no auth.
+// The variables are replaced in the JWT and OAuth2 fields, basic and bearer are replaced by the scenario with the captured ones too.
+func (a *Auth) provider(fallback auth.Provider, variables map[string]string) auth.Provider {
+	switch {
+	case a == nil:
+		return fallback
+	case a.Basic != nil:
+		return auth.Basic{Username: a.Basic.Username, Password: a.Basic.Password}
+	case a.JWT != nil:
+		return auth.JWT{
+			Algorithm: a.JWT.Algorithm,
+			Secret:    expand(a.JWT.Secret, variables),
+			KeyFile:   expand(a.JWT.KeyFile, variables),
+			KeyID:     a.JWT.KeyID,
+			Claims:    a.JWT.Claims,
+			ExpiresIn: time.Duration(a.JWT.ExpiresIn),
+		}
+	case a.OAuth2 != nil:
+		params := url.Values{}
+		for key, value := range a.OAuth2.Params {
+			params.Set(key, expand(value, variables))
+		}
+
+		return &auth.OAuth2{
+			TokenURL:     expand(a.OAuth2.TokenURL, variables),
+			ClientID:     expand(a.OAuth2.ClientID, variables),
+			ClientSecret: expand(a.OAuth2.ClientSecret, variables),
+			Scopes:       a.OAuth2.Scopes,
+			Params:       params,
+		}
+	}
+	return auth.Bearer{Token: a.Bearer}
+}
+
+// expand replaces the variables written as {{name}}, keeping the ones that are not defined
+func expand(value string, variables map[string]string) string {
+	return variablePattern.ReplaceAllStringFunc(value, func(match string) string {
+		replaced, ok := variables[variablePattern.FindStringSubmatch(match)[1]]
+		if !ok {
+			return match
+		}
+		return replaced
+	})
+}
+
+// options returns the options of the HTTP client, using the jar if cookies are enabled
+func (c *Client) options(jar http.CookieJar) call.ClientOptions {
+	if c == nil {
+		return call.ClientOptions{}
+	}
+
+	options := call.ClientOptions{
+		CAFile:             c.CAFile,
+		CertFile:           c.CertFile,
+		KeyFile:            c.KeyFile,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		NoRedirects:        c.NoRedirects,
+		MaxRedirects:       c.MaxRedirects,
+		Proxy:              c.Proxy,
+	}
+	if c.Cookies {
+		options.Jar = jar
+	}
+	return options
+}
+
+func (g *GRPC) tester(description string, assertions []assertion.Assertion, registry mock.Registry, provider auth.Provider) integration.Tester {
+	testCase := &integration.GRPCTestCase{
+		Description: description,
+		Call: call.Call{
+			Target:        g.Target,
+			Method:        g.Method,
+			ProtoFiles:    g.ProtoFiles,
+			ImportPaths:   g.ImportPaths,
+			DescriptorSet: g.DescriptorSet,
+			Metadata:      md(g.Metadata),
+			Auth:          provider,
+			Message:       message(g.Message),
+			Messages:      messages(g.Messages),
+			Timeout:       time.Duration(g.Timeout),
+		},
+		Output: expect.Output{
+			Message:      message(g.Output.Message),
+			Messages:     messages(g.Output.Messages),
+			Unordered:    g.Output.Unordered,
+			Exact:        g.Output.Exact,
+			IgnoreFields: g.Output.IgnoreFields,
+			Header:       md(g.Output.Header),
+			Trailer:      md(g.Output.Trailer),
+			Schema:       g.Output.Schema.schema(),
+		},
+		Assertions: assertions,
+		Mock:       registry,
+	}
+
+	if g.Output.Status != nil {
+		testCase.Output.Status = &expect.Status{
+			Code:            codeNames[g.Output.Status.Code],
+			Message:         g.Output.Status.Message,
+			MessageContains: g.Output.Status.MessageContains,
+			MessageRegex:    g.Output.Status.MessageRegex,
+			Details:         messages(g.Output.Status.Details),
+		}
+	}
+
+	return testCase
+}
+
+// schema returns the expected schema, or nil if it's not set
+func (s *Schema) schema() *expect.Schema {
+	if s == nil {
+		return nil
+	}
+	return &expect.Schema{File: s.File, Content: string(s.Content)}
+}
+
+// fields returns the expected fields of the response, or nil if there are none
+func fields(values []Field) []expect.Field {
+	if values == nil {
+		return nil
+	}
+
+	fields := []expect.Field{}
+	for _, value := range values {
+		fields = append(fields, expect.Field{
+			Path:     value.Path,
+			Operator: expect.Operator(value.Operator),
+			Value:    value.Value,
+		})
+	}
+	return fields
+}
+
+// cookies returns the expected cookies of the response, or nil if there are none
+func cookies(values []Cookie) []expect.Cookie {
+	if values == nil {
+		return nil
+	}
+
+	cookies := []expect.Cookie{}
+	for _, value := range values {
+		cookies = append(cookies, expect.Cookie{
+			Name:       value.Name,
+			Value:      value.Value,
+			ValueRegex: value.ValueRegex,
+			Path:       value.Path,
+			Domain:     value.Domain,
+			HttpOnly:   value.HttpOnly,
+			Secure:     value.Secure,
+			SameSite:   value.SameSite,
+			ExpiresIn:  time.Duration(value.ExpiresIn),
+		})
+	}
+	return cookies
+}
+
+// message returns a body as a JSON message, or nil if it's empty
+func message(body Body) interface{} {
+	if body == "" {
+		return nil
+	}
+	return string(body)
+}
+
+func messages(bodies []Body) []interface{} {
+	if bodies == nil {
+		return nil
+	}
+
+	values := []interface{}{}
+	for _, body := range bodies {
+		values = append(values, string(body))
+	}
+	return values
+}
+
+func md(values map[string]string) metadata.MD {
+	if values == nil {
+		return nil
+	}
+	return metadata.New(values)
+}
+
+func (a Assertion) assertion(databases map[string]*sql.DB) assertion.Assertion {
+	if a.Eventually != nil {
+		eventually := a.Eventually
+		a.Eventually = nil
+
+		return &assertion.Eventually{
+			Assertion: a.assertion(databases),
+			Timeout:   time.Duration(eventually.Timeout),
+			Interval:  time.Duration(eventually.Interval),
+			Backoff:   eventually.Backoff,
+		}
+	}
+
+	if a.SQL != nil {
+		return &assertion.SQL{
+			DB: databases[a.SQL.Database],
+			Query: call.Query{
+				Statement: a.SQL.Query,
+				Params:    a.SQL.Params,
+				Timeout:   time.Duration(a.SQL.Timeout),
+			},
+			Result: expect.Result(a.SQL.Result),
+		}
+	}
+
+	return &assertion.HTTP{
+		Request: expect.Request{
+			URL:    a.HTTP.Request.URL,
+			Method: a.HTTP.Request.Method,
+			Header: header(a.HTTP.Request.Header),
+			Body:   string(a.HTTP.Request.Body),
+			Times:  a.HTTP.Request.Times,
+		},
+		Response: mock.Response{
+			StatusCode: a.HTTP.Response.Status,
+			Body:       string(a.HTTP.Response.Body),
+		},
+	}
+}
@@ -0,0 +1,517 @@
+package suite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lucasvmiguel/integration/internal/chat"
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+func postsHandler(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/posts/1":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "title": "foo"}`))
+	case req.Method == http.MethodPost && req.URL.Path == "/posts":
+		body, _ := io.ReadAll(req.Body)
+
+		// notifies the service configured in the header, which is the mock server in the tests
+		resp, err := http.Post(req.Header.Get("X-Notify-URL")+"/notifications", "application/json", strings.NewReader(`{"title": "`+string(body)+`"}`))
+		if err == nil && resp.StatusCode != http.StatusAccepted {
+			http.Error(w, "notification failed", http.StatusBadGateway)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func database(t *testing.T) string {
+	dsn := filepath.Join(t.TempDir(), "suite.db")
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL); INSERT INTO posts (title) VALUES ('foo');")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dsn
+}
+
+func TestRun_YAML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(postsHandler))
+	defer server.Close()
+
+	s, err := Parse([]byte(fmt.Sprintf(`
+description: posts
+vars:
+  baseURL: %s
+databases:
+  main:
+    driver: sqlite3
+    dsn: %s
+mock: {}
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+      response:
+        status: 200
+        body:
+          id: 1
+          title: <<PRESENCE>>
+        fields:
+          - { path: id, operator: greaterThan, value: 0 }
+          - { path: title, operator: oneOf, value: [foo, bar] }
+        schema:
+          file: testdata/post.schema.json
+    assertions:
+      - sql:
+          database: main
+          query: SELECT id, title FROM posts WHERE id = ?
+          params: [1]
+          result:
+            - { id: 1, title: foo }
+        eventually:
+          timeout: 1s
+          interval: 10ms
+          backoff: 2
+    capture:
+      - name: title
+        path: title
+  - description: create post
+    http:
+      request:
+        url: "{{baseURL}}/posts"
+        method: POST
+        header:
+          X-Notify-URL: "{{mockURL}}"
+        body: "{{title}}"
+      response:
+        status: 201
+        body: foo
+    assertions:
+      - http:
+          request:
+            url: /notifications
+            method: POST
+            body: { title: "{{title}}" }
+          response:
+            status: 202
+`, server.URL, database(t))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Failed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(postsHandler))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+      response:
+        status: 404
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err == nil || !strings.Contains(err.Error(), "response status code should be 404 it got 200") {
+		t.Fatalf("it should fail due to the status code, it got %v", err)
+	}
+}
+
+func TestRun_Contract(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(postsHandler))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+contract: testdata/openapi.yaml
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+      response:
+        status: 200
+        body: { id: 1, title: foo }
+  - description: get missing post
+    http:
+      request:
+        url: "{{baseURL}}/posts/2"
+      response:
+        status: 404
+        body: "404 page not found\n"
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err == nil || !strings.Contains(err.Error(), "response of GET /posts/{id} does not match the contract: status is not supported") {
+		t.Fatalf("it should fail due to the contract, it got %v", err)
+	}
+
+	coverage := s.Coverage()
+	if coverage == nil || coverage.Covered() != 1 || coverage.Operations[1].Calls != 2 {
+		t.Fatalf("it should cover the get post operation twice, it got %v", coverage)
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-req.Context().Done()
+	}))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+cases:
+  - description: get post
+    http:
+      request:
+        url: "{{baseURL}}/posts/1"
+        timeout: 50ms
+      response:
+        status: 200
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Fatalf("it should fail due to timeout, it got %v", err)
+	}
+}
+
+func TestRun_Client(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/", HttpOnly: true, Secure: true})
+			w.Header().Set("Location", "/me")
+			w.WriteHeader(http.StatusFound)
+		case "/me":
+			cookie, err := req.Cookie("session")
+			if err != nil {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(cookie.Value))
+		}
+	}))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+client:
+  insecureSkipVerify: true
+  cookies: true
+cases:
+  - description: login without following the redirect
+    http:
+      request:
+        url: "{{baseURL}}/login"
+      client:
+        insecureSkipVerify: true
+        noRedirects: true
+        cookies: true
+      response:
+        status: 302
+        header:
+          Location: /me
+        cookies:
+          - name: session
+            value: abc
+            httpOnly: true
+            secure: true
+  - description: get user with the session cookie
+    http:
+      request:
+        url: "{{baseURL}}/me"
+      response:
+        status: 200
+        body: abc
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRun_Auth(t *testing.T) {
+	tokens := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/token":
+			tokens++
+			w.Write([]byte(`{"access_token": "client-token", "expires_in": 3600}`))
+		case "/login":
+			w.Write([]byte(`{"token": "user-token"}`))
+		default:
+			w.Write([]byte(req.Header.Get("Authorization")))
+		}
+	}))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+auth:
+  oauth2:
+    tokenURL: "{{baseURL}}/token"
+    clientID: client
+    clientSecret: secret
+cases:
+  - description: login
+    http:
+      request:
+        url: "{{baseURL}}/login"
+      response:
+        status: 200
+        body: { token: user-token }
+    capture:
+      - name: token
+        path: token
+  - description: client token is cached
+    http:
+      request:
+        url: "{{baseURL}}/me"
+      response:
+        status: 200
+        body: Bearer client-token
+  - description: user token
+    http:
+      request:
+        url: "{{baseURL}}/me"
+        auth:
+          bearer: "{{token}}"
+      response:
+        status: 200
+        body: Bearer user-token
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tokens != 1 {
+		t.Fatalf("oauth2 token should be requested once, it was requested %d times", tokens)
+	}
+}
+
+func TestRun_Payload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		err := req.ParseMultipartForm(1024)
+		if err != nil && err != http.ErrNotMultipart {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Write([]byte(req.FormValue("title")))
+		if req.MultipartForm != nil {
+			file, header, err := req.FormFile("avatar")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			content, _ := io.ReadAll(file)
+			w.Write([]byte(" " + header.Filename + ":" + string(content)))
+		}
+	}))
+	defer server.Close()
+
+	s, err := Parse([]byte(`
+cases:
+  - description: form
+    http:
+      request:
+        url: "{{baseURL}}"
+        method: POST
+        form:
+          title: "{{title}}"
+      response:
+        status: 200
+        body: foo
+  - description: multipart
+    http:
+      request:
+        url: "{{baseURL}}"
+        method: POST
+        multipart:
+          fields:
+            title: bar
+          files:
+            - field: avatar
+              path: testdata/avatar.txt
+      response:
+        status: 200
+        body: bar avatar.txt:avatar
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL, "title": "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+type chatServer struct {
+	chat.UnimplementedChatServiceServer
+}
+
+func (s *chatServer) SayHello(ctx context.Context, in *chat.Message) (*chat.Message, error) {
+	if in.Body == "" {
+		return nil, status.Error(codes.InvalidArgument, "body is required")
+	}
+	return &chat.Message{Id: in.Id, Body: "Hello " + in.Body}, nil
+}
+
+func TestRun_GRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	chat.RegisterChatServiceServer(server, &chatServer{})
+	reflection.Register(server)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	s, err := Parse([]byte(`
+cases:
+  - description: say hello
+    grpc:
+      target: "{{target}}"
+      method: chat.ChatService/SayHello
+      message:
+        id: 1
+        body: foo
+      output:
+        message:
+          id: 1
+          body: <<PRESENCE>>
+        schema:
+          content:
+            type: object
+            required: [id, body]
+    capture:
+      - name: greeting
+        path: body
+  - description: say hello again
+    grpc:
+      target: "{{target}}"
+      method: chat.ChatService/SayHello
+      message: { body: "{{greeting}}" }
+      output:
+        message: { id: 2, body: Hello Hello foo }
+        exact: true
+        ignoreFields: [id]
+  - description: say nothing
+    grpc:
+      target: "{{target}}"
+      method: chat.ChatService/SayHello
+      message: {}
+      output:
+        status:
+          code: InvalidArgument
+          messageContains: required
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = s.Run(map[string]string{"target": lis.Addr().String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_JSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(postsHandler))
+	defer server.Close()
+
+	s, err := Load("testdata/posts.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Cases) != 2 || s.Cases[0].HTTP.Response.Header["Content-Type"] != "application/json" {
+		t.Fatalf("suite was not loaded correctly: %+v", s)
+	}
+
+	err = s.Run(map[string]string{"baseURL": server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad_NotFound(t *testing.T) {
+	_, err := Load("testdata/unknown.yaml")
+	if err == nil {
+		t.Fatal("it should fail due to a file that does not exist")
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no cases":           `description: foo`,
+		"unknown field":      "cases:\n  - http: {}\n    unknown: true",
+		"no http":            "cases:\n  - description: foo",
+		"http and websocket": "cases:\n  - http: {}\n    websocket: {}",
+		"http without mock":  "cases:\n  - http: {}\n    assertions:\n      - http: {}",
+		"unknown database":   "cases:\n  - http: {}\n    assertions:\n      - sql: { database: foo }",
+		"invalid duration":   "cases:\n  - websocket: { receive: { timeout: foo } }",
+		"http and grpc":      "cases:\n  - http: {}\n    grpc: {}",
+		"invalid grpc code":  "cases:\n  - grpc: { output: { status: { code: Foo } } }",
+		"two auths":          "auth: { bearer: foo, basic: {} }\ncases:\n  - http: {}",
+		"case without auth":  "cases:\n  - grpc: { auth: {} }",
+		"body and form":      "cases:\n  - http: { request: { body: foo, form: { foo: bar } } }",
+	}
+
+	for name, content := range tests {
+		_, err := Parse([]byte(content))
+		if err == nil {
+			t.Fatalf("%s: it should fail to parse", name)
+		}
+	}
+}
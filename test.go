@@ -1,7 +1,9 @@
 package integration
 
 import (
+	"context"
 	"fmt"
+	"time"
 )
 
 // Tester allows to test a case
@@ -9,9 +11,30 @@ type Tester interface {
 	Test() error
 }
 
-// Test runs a test case
+// ContextTester allows to test a case that can be canceled by a context
+type ContextTester interface {
+	Tester
+	TestContext(ctx context.Context) error
+}
+
+// Test runs a test case and notifies the reporters
 func Test(tester Tester) error {
-	return tester.Test()
+	return Evaluate(tester).Err()
+}
+
+// TestContext runs a test case with a context and notifies the reporters.
+// The test case stops when the context is canceled or its deadline is exceeded.
+// If the test case isn't a ContextTester, the context is ignored.
+func TestContext(ctx context.Context, tester Tester) error {
+	return EvaluateContext(ctx, tester).Err()
+}
+
+// withTimeout returns a context with the timeout, or the same context if the timeout is not set
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
 }
 
 func errString(err error, description string, message string) string {
@@ -2,18 +2,21 @@ package integration
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/jarcoal/httpmock"
-	"github.com/kinbiko/jsonassert"
 	"github.com/lucasvmiguel/integration/assertion"
 	"github.com/lucasvmiguel/integration/call"
 	"github.com/lucasvmiguel/integration/expect"
 	"github.com/lucasvmiguel/integration/internal/utils"
+	"github.com/lucasvmiguel/integration/matcher"
+	"github.com/lucasvmiguel/integration/mock"
 	"github.com/lucasvmiguel/integration/ws"
 )
 
@@ -33,30 +36,52 @@ type WebsocketTestCase struct {
 	// Assertions that will run in test case
 	Assertions []assertion.Assertion
 
+	// Mock is the registry used by the HTTP assertions of the test case (eg: mock.NewTransport()).
+	// If it's nil, the HTTP assertions use the global httpmock transport, which can't run in parallel.
+	Mock mock.Registry
+
 	connection *ws.WebsocketConnection
 }
 
 // Test runs an Websocket test case
 func (t *WebsocketTestCase) Test() error {
+	return t.TestContext(context.Background())
+}
+
+// TestContext runs an Websocket test case, the context is used to cancel connecting, sending and receiving
+func (t *WebsocketTestCase) TestContext(ctx context.Context) error {
+	return t.evaluate(ctx).Err()
+}
+
+func (t *WebsocketTestCase) evaluate(ctx context.Context) *Result {
+	result := newResult(t.Description, "Websocket")
+	defer result.finish()
+
 	err := t.validate()
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to validate test case"))
+		return result.fail(PhaseValidate, "failed to validate test case", err)
 	}
 
-	if assertion.AnyHTTP(t.Assertions) {
+	assertions := assertion.WithMock(t.Assertions, t.Mock)
+
+	if assertion.AnyGlobalHTTP(assertions) {
 		httpmock.Activate()
 		defer httpmock.DeactivateAndReset()
 	}
 
-	err = t.setupAssertions()
+	err = t.setupAssertions(assertions)
 	if err != nil {
-		return errors.New(errString(err, t.Description, "failed to setup assertions"))
+		return result.fail(PhaseSetup, "failed to setup assertions", err)
 	}
 
+	// the timeout limits the call only, the assertions run with the context of the test case
+	callCtx, cancel := withTimeout(ctx, t.Call.Timeout)
+	defer cancel()
+
 	if t.Call.Connection == nil {
-		conn, err := t.connect()
+		conn, err := t.connect(callCtx)
 		if err != nil {
-			return errors.New(errString(err, t.Description, "failed to connect Websocket endpoint"))
+			return result.fail(PhaseCall, "failed to connect Websocket endpoint", err)
 		}
 		t.connection = conn
 	} else {
@@ -66,41 +91,41 @@ func (t *WebsocketTestCase) Test() error {
 	var resp []byte
 
 	if t.Call.MessageType == websocket.PingMessage {
-		resp, err = t.readAndSendPing()
+		resp, err = t.readAndSendPing(callCtx)
 		if err != nil {
-			return errors.New(errString(err, t.Description, "failed to read and send ping message"))
+			return result.fail(PhaseCall, "failed to read and send ping message", err)
 		}
 	} else {
-		resp, err = t.readAndSendMessage()
+		resp, err = t.readAndSendMessage(callCtx)
 		if err != nil {
-			return errors.New(errString(err, t.Description, "failed to read and send message"))
+			return result.fail(PhaseCall, "failed to read and send message", err)
 		}
 	}
 
+	result.response = &response{body: resp}
+
 	if t.Receive != nil {
 		err = t.assert(resp)
 		if err != nil {
-			return errors.New(errString(err, t.Description, "failed to assert Websocket response"))
+			result.fail(PhaseAssert, "failed to assert Websocket response", err)
 		}
 	}
 
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Assert()
-			if err != nil {
-				return errors.New(errString(err, t.Description, "failed to assert"))
-			}
+	for i, a := range assertions {
+		err := assertion.AssertContext(ctx, a)
+		if err != nil {
+			result.failAssertion(i+1, err)
 		}
 	}
 
 	if t.Call.CloseConnectionAfterCall {
 		err = t.connection.Close()
 		if err != nil {
-			return errors.New(errString(err, t.Description, "failed to close Websocket connection"))
+			result.fail(PhaseCall, "failed to close Websocket connection", err)
 		}
 	}
 
-	return nil
+	return result
 }
 
 // Connection returns the Websocket connection
@@ -108,21 +133,19 @@ func (t *WebsocketTestCase) Connection() *ws.WebsocketConnection {
 	return t.connection
 }
 
-func (t *WebsocketTestCase) setupAssertions() error {
+func (t *WebsocketTestCase) setupAssertions(assertions []assertion.Assertion) error {
 
-	if t.Assertions != nil {
-		for _, assertion := range t.Assertions {
-			err := assertion.Setup()
-			if err != nil {
-				return fmt.Errorf("failed to setup assertion: %w", err)
-			}
+	for _, assertion := range assertions {
+		err := assertion.Setup()
+		if err != nil {
+			return fmt.Errorf("failed to setup assertion: %w", err)
 		}
 	}
 
 	return nil
 }
 
-func (t *WebsocketTestCase) readAndSendMessage() ([]byte, error) {
+func (t *WebsocketTestCase) readAndSendMessage(ctx context.Context) ([]byte, error) {
 	messageType := t.Call.MessageType
 	if messageType == 0 {
 		messageType = websocket.TextMessage
@@ -137,8 +160,8 @@ func (t *WebsocketTestCase) readAndSendMessage() ([]byte, error) {
 	}
 
 	var resp []byte
-	msg := make(chan []byte)
-	errChan := make(chan error)
+	msg := make(chan []byte, 1)
+	errChan := make(chan error, 1)
 
 	go func() {
 		_, m, err := t.connection.Read()
@@ -161,11 +184,13 @@ func (t *WebsocketTestCase) readAndSendMessage() ([]byte, error) {
 	case err := <-errChan:
 		return nil, fmt.Errorf("failed to send read message, channel error: %w", err)
 	case <-time.After(t.timeout()):
-		return nil, errors.New("timeout to reading message from the Websocket server")
+		return nil, fmt.Errorf("timeout to reading message from the Websocket server: %w", context.DeadlineExceeded)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to read message from the Websocket server: %w", ctx.Err())
 	}
 }
 
-func (t *WebsocketTestCase) readAndSendPing() ([]byte, error) {
+func (t *WebsocketTestCase) readAndSendPing(ctx context.Context) ([]byte, error) {
 	if t.Receive == nil {
 		err := t.connection.Send(t.Call.MessageType, []byte(t.Call.Message))
 		if err != nil {
@@ -174,7 +199,7 @@ func (t *WebsocketTestCase) readAndSendPing() ([]byte, error) {
 		return nil, nil
 	}
 
-	msg := make(chan []byte)
+	msg := make(chan []byte, 1)
 
 	t.connection.SetPongHandler(func(data string) error {
 		m := []byte(data)
@@ -194,7 +219,9 @@ func (t *WebsocketTestCase) readAndSendPing() ([]byte, error) {
 	case resp := <-msg:
 		return resp, nil
 	case <-time.After(t.timeout()):
-		return nil, errors.New("timeout to reading pong from the Websocket server")
+		return nil, fmt.Errorf("timeout to reading pong from the Websocket server: %w", context.DeadlineExceeded)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("failed to read pong from the Websocket server: %w", ctx.Err())
 	}
 }
 
@@ -206,25 +233,69 @@ func (t *WebsocketTestCase) assert(message []byte) error {
 
 	contentString := string(content)
 
+	if t.Receive.Schema != nil {
+		err := assertSchema("message content", t.Receive.Schema, content)
+		if err != nil {
+			return err
+		}
+	}
+
+	if t.Receive.Content == "" && t.Receive.Schema != nil {
+		// the content is only validated by the schema
+		return nil
+	}
+
 	if utils.IsJSON(t.Receive.Content) {
-		je := utils.JsonError{}
-		jsonassert.New(&je).Assertf(contentString, t.Receive.Content)
-		if je.Err != nil {
-			return fmt.Errorf("content is a JSON. content does not match: %v", je.Err.Error())
+		err := matcher.JSON(contentString, t.Receive.Content)
+		if err != nil {
+			return &mismatchError{
+				err:      fmt.Errorf("content is a JSON. content does not match: %v", err),
+				expected: t.Receive.Content,
+				actual:   contentString,
+				diff:     bodyDiff(t.Receive.Content, contentString),
+			}
+		}
+	} else if matcher.IsPlaceholder(t.Receive.Content) {
+		err := matcher.Match(contentString, t.Receive.Content)
+		if err != nil {
+			return &mismatchError{
+				err:      fmt.Errorf("content does not match %s: %w", t.Receive.Content, err),
+				expected: t.Receive.Content,
+				actual:   contentString,
+			}
 		}
 	} else {
 		if contentString != t.Receive.Content {
-			return fmt.Errorf("content is a regular string. content should be '%s' it got '%s'", t.Receive.Content, contentString)
+			return &mismatchError{
+				err:      fmt.Errorf("content is a regular string. content should be '%s' it got '%s'", t.Receive.Content, contentString),
+				expected: t.Receive.Content,
+				actual:   contentString,
+				diff:     bodyDiff(t.Receive.Content, contentString),
+			}
 		}
 	}
 
 	return nil
 }
 
-func (t *WebsocketTestCase) connect() (*ws.WebsocketConnection, error) {
-	conn, err := ws.NewWebsocketConnection(string(t.Call.Scheme), t.Call.URL, t.Call.Path, t.Call.Header)
+func (t *WebsocketTestCase) connect(ctx context.Context) (*ws.WebsocketConnection, error) {
+	header := t.Call.Header
+	if t.Call.Auth != nil {
+		authorization, err := t.Call.Auth.Authorization(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get authorization: %w", err)
+		}
+
+		header = header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Set("Authorization", authorization)
+	}
+
+	conn, err := ws.NewWebsocketConnectionContext(ctx, string(t.Call.Scheme), t.Call.URL, t.Call.Path, header)
 	if err != nil {
-		return nil, fmt.Errorf("error to connect to the Websocket server: %s", err.Error())
+		return nil, fmt.Errorf("error to connect to the Websocket server: %w", err)
 	}
 	return conn, nil
 }
@@ -243,10 +314,36 @@ func (t *WebsocketTestCase) timeout() time.Duration {
 	return timeout
 }
 
+func (t *WebsocketTestCase) interpolate(vars map[string]string) (Tester, error) {
+	i := interpolation{vars: vars}
+
+	interpolated := *t
+	interpolated.Call.URL = i.string(t.Call.URL)
+	interpolated.Call.Path = i.string(t.Call.Path)
+	interpolated.Call.Header = i.header(t.Call.Header)
+	interpolated.Call.Auth = i.auth(t.Call.Auth)
+	interpolated.Call.Message = i.string(t.Call.Message)
+	if t.Receive != nil {
+		receive := *t.Receive
+		receive.Content = i.string(t.Receive.Content)
+		interpolated.Receive = &receive
+	}
+	interpolated.Assertions = i.assertions(t.Assertions)
+
+	return &interpolated, i.err
+}
+
 func (t *WebsocketTestCase) validate() error {
 	if t.Call.Connection == nil && t.Call.URL == "" {
 		return errors.New("URL is required when Connection is nil")
 	}
 
+	if t.Receive != nil && t.Receive.Schema != nil {
+		_, err := compileSchema(t.Receive.Schema)
+		if err != nil {
+			return fmt.Errorf("receive schema is invalid: %w", err)
+		}
+	}
+
 	return nil
 }
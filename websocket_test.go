@@ -596,3 +596,20 @@ func TestWebsocket_SuccessCloseConnection(t *testing.T) {
 		t.Fatal("it should return an error due to a closed connection")
 	}
 }
+
+func TestWebsocket_Timeout(t *testing.T) {
+	result := Evaluate(&WebsocketTestCase{
+		Description: "TestWebsocket_Timeout",
+		Call: call.Websocket{
+			URL:     fmt.Sprintf("localhost:%d", 8090),
+			Path:    "/handler-infinite",
+			Message: "hello",
+			Timeout: time.Nanosecond,
+		},
+		Receive: &expect.Message{Content: "hello"},
+	})
+
+	if len(result.Failures) != 1 || result.Failures[0].Kind != KindTimeout {
+		t.Fatalf("it should fail due to timeout, it got %v", result.Err())
+	}
+}
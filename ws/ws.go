@@ -1,6 +1,7 @@
 package ws
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -17,15 +18,20 @@ type WebsocketConnection struct {
 
 // NewWebsocketConnection creates a new Websocket connection
 func NewWebsocketConnection(scheme, host, path string, headers http.Header) (*WebsocketConnection, error) {
+	return NewWebsocketConnectionContext(context.Background(), scheme, host, path, headers)
+}
+
+// NewWebsocketConnectionContext creates a new Websocket connection, the context is used to cancel the connection attempt
+func NewWebsocketConnectionContext(ctx context.Context, scheme, host, path string, headers http.Header) (*WebsocketConnection, error) {
 	if scheme == "" {
 		scheme = "ws"
 	}
 
 	u := url.URL{Scheme: scheme, Host: host, Path: path}
 
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), headers)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u.String(), headers)
 	if err != nil {
-		return nil, fmt.Errorf("error to connect to the Websocket server (%s): %s", u.String(), err.Error())
+		return nil, fmt.Errorf("error to connect to the Websocket server (%s): %w", u.String(), err)
 	}
 
 	return &WebsocketConnection{